@@ -3,10 +3,15 @@ package main
 import (
 	"fmt"
 	"github.com/dalmarcogd/bpl-go/internal/handlers"
+	admin2 "github.com/dalmarcogd/bpl-go/internal/infra/admin"
 	cache2 "github.com/dalmarcogd/bpl-go/internal/infra/cache"
 	database2 "github.com/dalmarcogd/bpl-go/internal/infra/database"
 	environment2 "github.com/dalmarcogd/bpl-go/internal/infra/environment"
+	"github.com/dalmarcogd/bpl-go/internal/infra/http"
 	logger2 "github.com/dalmarcogd/bpl-go/internal/infra/logger"
+	metrics2 "github.com/dalmarcogd/bpl-go/internal/infra/metrics"
+	spans2 "github.com/dalmarcogd/bpl-go/internal/infra/spans"
+	validator2 "github.com/dalmarcogd/bpl-go/internal/infra/validator"
 	"github.com/dalmarcogd/bpl-go/internal/services"
 	"os"
 	"os/signal"
@@ -18,7 +23,11 @@ func main() {
 		WithDatabase(database2.New()).
 		WithCache(cache2.New()).
 		WithLogger(logger2.New()).
-		WithHttpServer(http.New().WithAddress(":8080")).
+		WithSpans(spans2.New()).
+		WithMetrics(metrics2.New()).
+		WithValidator(validator2.New()).
+		WithHttpServer(http.New()).
+		WithAdminServer(admin2.New()).
 		WithHandlers(handlers.New()).
 		WithEnvironment(environment2.New())
 
@@ -35,15 +44,23 @@ func main() {
 		}
 	}()
 
+	go func() {
+		ss.Logger().Info(ss.Context(), "Admin server started")
+		if err := ss.AdminServer().Run(); err != nil {
+			ss.Logger().Fatal(ss.Context(), err.Error())
+			return
+		}
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, os.Kill)
 	sig := <-quit
 
 	ss.Logger().Info(ss.Context(), fmt.Sprintf("Shutdown by %v", sig.String()))
 
+	ss.Logger().Info(ss.Context(), "Closing all services")
 	if err := ss.Close(); err != nil {
 		ss.Logger().Fatal(ss.Context(), err.Error())
 		return
 	}
-	ss.Logger().Info(ss.Context(), "All services closed")
 }
@@ -20,7 +20,7 @@ func main() {
 		return
 	}
 
-	if err := ss.Database().DB(ss.Context()).AutoMigrate(&models.User{}); err != nil {
+	if err := ss.Database().DB(ss.Context()).AutoMigrate(&models.User{}, &models.ApiKey{}); err != nil {
 		ss.Logger().Fatal(ss.Context(), err.Error())
 		return
 	}
@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error is a domain error with a stable code, its http status and optional details.
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+	Details map[string]interface{}
+	Err     error
+}
+
+func NewError(code string, status int, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+func NotFound(code string, message string) *Error {
+	return NewError(code, http.StatusNotFound, message)
+}
+
+func Conflict(code string, message string) *Error {
+	return NewError(code, http.StatusConflict, message)
+}
+
+func Invalid(code string, message string) *Error {
+	return NewError(code, http.StatusUnprocessableEntity, message)
+}
+
+func Internal(code string, message string) *Error {
+	return NewError(code, http.StatusInternalServerError, message)
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors with the same code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && e.Code == t.Code
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// WithDetails returns a copy of e with details added to its own.
+func (e *Error) WithDetails(details map[string]interface{}) *Error {
+	c := *e
+	c.Details = make(map[string]interface{}, len(e.Details)+len(details))
+	for k, v := range e.Details {
+		c.Details[k] = v
+	}
+	for k, v := range details {
+		c.Details[k] = v
+	}
+	return &c
+}
+
+// As finds the first Error in the chain of err.
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
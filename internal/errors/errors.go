@@ -1,8 +1,66 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+)
 
 var (
-	UserNotFound = errors.New("user not found")
-	UserIdRequired = errors.New("user id is required")
+	InternalError = Internal("internal", "internal server error")
+
+	UserNotFound      = NotFound("user_not_found", "user not found")
+	UserIdRequired    = Invalid("user_id_required", "user id is required")
+	UserAlreadyExists = Conflict("user_already_exists", "user already exists")
+
+	ApiKeyNotFound         = NotFound("api_key_not_found", "api key not found")
+	ApiKeyIdRequired       = Invalid("api_key_id_required", "api key id is required")
+	ApiKeyExpiresAtInvalid = Invalid("api_key_expires_at_invalid", "api key expiration must be in the future")
+
+	CacheKeyNotFound        = errors.New("cache key not found")
+	CacheModeUnsupported    = errors.New("cache mode must be standalone, sentinel or cluster")
+	CacheMasterNameRequired = errors.New("cache master name is required on sentinel mode")
+	CacheTLSCAInvalid       = errors.New("cache tls ca file has no valid certificate")
+	CacheLockNotAcquired    = errors.New("cache lock is held by someone else")
+	CacheLockNotHeld        = errors.New("cache lock is no longer held")
+
+	RateLimitAlgorithmUnsupported = errors.New("rate limit algorithm must be token_bucket or sliding_window")
+	RateLimitReplyInvalid         = errors.New("rate limit reply is invalid")
+	RateLimitInvalid              = errors.New("rate limit limit and period must be greater than zero")
+	RateLimitRouteInvalid         = errors.New("rate limit route must be METHOD /path=limit/period_ms")
+	RateLimitExceeded             = NewError("rate_limit_exceeded", http.StatusTooManyRequests, "rate limit exceeded")
+
+	LogLevelInvalid             = errors.New("log level must be trace, debug, info, warning, error or fatal")
+	LogPackageRequired          = errors.New("log package is required")
+	LogPackageLevelInvalid      = errors.New("log package level must be package=level")
+	LogFormatUnsupported        = errors.New("log format must be json, logfmt or console")
+	LogSyslogUnsupported        = errors.New("log syslog is not supported on this platform")
+	LogRedactPatternUnsupported = errors.New("log redact pattern must be email or card")
+
+	IdempotencyKeyInvalid    = NewError("idempotency_key_invalid", http.StatusBadRequest, "idempotency key must have at most 255 characters")
+	IdempotencyKeyInProgress = Conflict("idempotency_key_in_progress", "a request with this idempotency key is still in progress")
+	IdempotencyKeyReused     = Invalid("idempotency_key_reused", "idempotency key was already used with another request")
+
+	ValidatorObjsNotSlice  = errors.New("objs must be a slice")
+	ValidationFailed       = Invalid("validation_failed", "request is invalid")
+	ContractRequestInvalid = NewError("request_invalid", http.StatusBadRequest, "request does not match the contract")
+	RequestBodyTooLarge    = NewError("request_body_too_large", http.StatusRequestEntityTooLarge, "request body is too large")
+
+	TLSKeyPairRequired       = errors.New("tls requires both the certificate and the key files")
+	TLSMinVersionUnsupported = errors.New("tls min version must be 1.2 or 1.3")
+	TLSCipherUnsupported     = errors.New("tls cipher suite is unknown or insecure")
+	TLSClientAuthUnsupported = errors.New("tls client auth must be require or optional")
+	TLSClientCAInvalid       = errors.New("tls client ca file has no valid certificate")
+
+	HttpTrustedProxyInvalid      = errors.New("http trusted proxy must be a cidr")
+	CORSAnyOriginWithCredentials = errors.New("cors allows any origin only without credentials")
+
+	TraceParentInvalid = errors.New("traceparent header must be 00-{trace id}-{parent id}-{flags}")
+
+	AuthenticationRequired = NewError("authentication_required", http.StatusUnauthorized, "authentication is required")
+	AuthenticationInvalid  = NewError("authentication_invalid", http.StatusUnauthorized, "credentials are invalid or expired")
+	PermissionDenied       = NewError("permission_denied", http.StatusForbidden, "permission denied")
+	AuthKeySourceRequired  = errors.New("auth requires a jwks file or url, or api keys")
+	AuthJWKSInvalid        = errors.New("auth jwks is invalid")
+	AuthJWKNotFound        = errors.New("auth jwk of the token not found")
+	AuthJWKAlgMismatch     = errors.New("auth jwk does not match the algorithm of the token")
 )
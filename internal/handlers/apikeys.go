@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	goerrors "errors"
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+const (
+	apiKeyPrefix       = "bpl"
+	apiKeyPrefixBytes  = 6
+	apiKeySecretBytes  = 32
+	apiKeySeparator    = "_"
+	apiKeyLastUsedStep = time.Minute
+)
+
+// CreateApiKey stores key with a new secret and returns it as bpl_<prefix>_<secret>, only once.
+func (s *ServiceImpl) CreateApiKey(ctx context.Context, key *models.ApiKey) (string, error) {
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		return "", errors.ApiKeyExpiresAtInvalid
+	}
+	prefix := make([]byte, apiKeyPrefixBytes)
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(prefix); err != nil {
+		return "", errors.InternalError.Wrap(err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.InternalError.Wrap(err)
+	}
+	key.Id = uuid.New().String()
+	key.Prefix = hex.EncodeToString(prefix)
+	plain := strings.Join([]string{apiKeyPrefix, key.Prefix, base64.RawURLEncoding.EncodeToString(secret)}, apiKeySeparator)
+	key.Hash = apiKeyHash(plain)
+
+	if result := s.Sis().Database().DB(ctx).Create(key); result.Error != nil {
+		return "", apiKeyError(result.Error)
+	}
+	s.logger(ctx).Info(ctx, "Api key created", map[string]interface{}{"api_key_id": key.Id, "prefix": key.Prefix})
+	return plain, nil
+}
+
+func (s *ServiceImpl) GetApiKeys(ctx context.Context, keys *[]models.ApiKey) error {
+	result := s.Sis().Database().DB(ctx).Order("created_at").Find(keys)
+	if result.Error != nil {
+		return apiKeyError(result.Error)
+	}
+	return nil
+}
+
+// RevokeApiKey makes key unusable, revoking it again keeps the first revocation.
+func (s *ServiceImpl) RevokeApiKey(ctx context.Context, key *models.ApiKey) error {
+	db := s.Sis().Database().DB(ctx)
+	if result := db.Where("id = ?", key.Id).First(key); result.Error != nil {
+		return apiKeyError(result.Error)
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	if result := db.Model(key).Update("revoked_at", now); result.Error != nil {
+		return apiKeyError(result.Error)
+	}
+	s.logger(ctx).Info(ctx, "Api key revoked", map[string]interface{}{"api_key_id": key.Id})
+	return nil
+}
+
+// AuthenticateApiKey returns the key of plain when it is neither revoked nor expired.
+func (s *ServiceImpl) AuthenticateApiKey(ctx context.Context, plain string) (*models.ApiKey, error) {
+	parts := strings.SplitN(plain, apiKeySeparator, 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" {
+		return nil, errors.AuthenticationInvalid.Wrap(fmt.Errorf("api key is malformed"))
+	}
+	db := s.Sis().Database().DB(ctx)
+	key := new(models.ApiKey)
+	if result := db.Where("prefix = ?", parts[1]).First(key); result.Error != nil {
+		if goerrors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.AuthenticationInvalid.Wrap(result.Error)
+		}
+		return nil, apiKeyError(result.Error)
+	}
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(apiKeyHash(plain))) != 1 {
+		return nil, errors.AuthenticationInvalid.Wrap(fmt.Errorf("api key %v secret mismatch", key.Prefix))
+	}
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return nil, errors.AuthenticationInvalid.Wrap(fmt.Errorf("api key %v revoked", key.Prefix))
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return nil, errors.AuthenticationInvalid.Wrap(fmt.Errorf("api key %v expired", key.Prefix))
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyLastUsedStep {
+		key.LastUsedAt = &now
+		if result := db.Model(key).UpdateColumn("last_used_at", now); result.Error != nil {
+			s.logger(ctx).Warn(ctx, "Api key last use not recorded", map[string]interface{}{
+				"api_key_id": key.Id,
+				"error":      result.Error,
+			})
+		}
+	}
+	return key, nil
+}
+
+// apiKeyHash is a plain sha256, a slow hash adds nothing to random secrets of 256 bits.
+func apiKeyHash(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func apiKeyError(err error) error {
+	if goerrors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.ApiKeyNotFound.Wrap(err)
+	}
+	return errors.InternalError.Wrap(err)
+}
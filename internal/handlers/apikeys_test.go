@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	goerrors "errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+var apiKeyColumns = []string{"id", "name", "prefix", "hash", "scopes", "tenant", "created_by", "created_at", "updated_at", "expires_at", "last_used_at", "revoked_at"}
+
+func TestServiceImpl_CreateApiKey(t *testing.T) {
+	handlers, mock := newMockHandlers(t)
+
+	past := time.Now().Add(-time.Minute)
+	if _, err := handlers.CreateApiKey(context.Background(), &models.ApiKey{Name: "ci", ExpiresAt: &past}); !goerrors.Is(err, errors.ApiKeyExpiresAtInvalid) {
+		t.Errorf("CreateApiKey() expired = %v, want %v", err, errors.ApiKeyExpiresAtInvalid)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "api_keys"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	key := &models.ApiKey{Name: "ci", Scopes: "users:read"}
+	plain, err := handlers.CreateApiKey(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the secret is base64url, which may contain the separator
+	parts := strings.SplitN(plain, apiKeySeparator, 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] != key.Prefix || parts[2] == "" {
+		t.Errorf("key = %v, want bpl_<prefix>_<secret>", plain)
+	}
+	if key.Hash != apiKeyHash(plain) {
+		t.Errorf("hash = %v, want the sha256 of the key", key.Hash)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServiceImpl_RevokeApiKey(t *testing.T) {
+	handlers, mock := newMockHandlers(t)
+	revokedAt := time.Now().Add(-time.Hour)
+
+	mock.ExpectQuery(`SELECT \* FROM "api_keys" WHERE id = \$1`).WithArgs("unknown", "unknown").
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns))
+	if err := handlers.RevokeApiKey(context.Background(), &models.ApiKey{Id: "unknown"}); !goerrors.Is(err, errors.ApiKeyNotFound) {
+		t.Errorf("RevokeApiKey() unknown = %v, want %v", err, errors.ApiKeyNotFound)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "api_keys" WHERE id = \$1`).WithArgs("revoked", "revoked").
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns).AddRow("revoked", "ci", "a1", "h", "", nil, nil, time.Now(), time.Now(), nil, nil, revokedAt))
+	key := &models.ApiKey{Id: "revoked"}
+	if err := handlers.RevokeApiKey(context.Background(), key); err != nil || !key.RevokedAt.Equal(revokedAt) {
+		t.Errorf("RevokeApiKey() revoked = %v %v, want the first revocation kept", err, key.RevokedAt)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "api_keys" WHERE id = \$1`).WithArgs("active", "active").
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns).AddRow("active", "ci", "a2", "h", "", nil, nil, time.Now(), time.Now(), nil, nil, nil))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "api_keys" SET "revoked_at"=\$1,"updated_at"=\$2 WHERE "id" = \$3`).
+		WithArgs(anyTime{}, anyTime{}, "active").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	key = &models.ApiKey{Id: "active"}
+	if err := handlers.RevokeApiKey(context.Background(), key); err != nil || key.RevokedAt == nil {
+		t.Errorf("RevokeApiKey() active = %v %v, want it revoked", err, key.RevokedAt)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServiceImpl_AuthenticateApiKey(t *testing.T) {
+	handlers, mock := newMockHandlers(t)
+	plain := "bpl_a1b2c3_se_cret"
+	past, future, recent := time.Now().Add(-time.Hour), time.Now().Add(time.Hour), time.Now()
+
+	tests := []struct {
+		name       string
+		plain      string
+		row        []driver.Value
+		recordUse  bool
+		wantErr    error
+		wantScopes string
+	}{
+		{name: "malformed", plain: "a1b2c3_secret", wantErr: errors.AuthenticationInvalid},
+		{name: "unknown", plain: plain, wantErr: errors.AuthenticationInvalid},
+		{name: "secret mismatch", plain: plain, row: []driver.Value{"k", "ci", "a1b2c3", apiKeyHash("bpl_a1b2c3_other"), "admin", nil, nil, past, past, nil, nil, nil}, wantErr: errors.AuthenticationInvalid},
+		{name: "revoked", plain: plain, row: []driver.Value{"k", "ci", "a1b2c3", apiKeyHash(plain), "admin", nil, nil, past, past, nil, nil, past}, wantErr: errors.AuthenticationInvalid},
+		{name: "expired", plain: plain, row: []driver.Value{"k", "ci", "a1b2c3", apiKeyHash(plain), "admin", nil, nil, past, past, past, nil, nil}, wantErr: errors.AuthenticationInvalid},
+		{name: "first use", plain: plain, row: []driver.Value{"k", "ci", "a1b2c3", apiKeyHash(plain), "admin", nil, nil, past, past, future, nil, nil}, recordUse: true, wantScopes: "admin"},
+		{name: "recent use", plain: plain, row: []driver.Value{"k", "ci", "a1b2c3", apiKeyHash(plain), "admin", nil, nil, past, past, nil, recent, nil}, wantScopes: "admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.plain == plain {
+				rows := sqlmock.NewRows(apiKeyColumns)
+				if tt.row != nil {
+					rows.AddRow(tt.row...)
+				}
+				mock.ExpectQuery(`SELECT \* FROM "api_keys" WHERE prefix = \$1`).WithArgs("a1b2c3").WillReturnRows(rows)
+			}
+			if tt.recordUse {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "api_keys" SET "last_used_at"=\$1 WHERE "id" = \$2`).
+					WithArgs(anyTime{}, "k").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			}
+
+			key, err := handlers.AuthenticateApiKey(context.Background(), tt.plain)
+			if !goerrors.Is(err, tt.wantErr) {
+				t.Errorf("AuthenticateApiKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && key.Scopes != tt.wantScopes {
+				t.Errorf("scopes = %v, want %v", key.Scopes, tt.wantScopes)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -2,13 +2,21 @@ package handlers
 
 import (
 	"context"
+	goerrors "errors"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
 	"github.com/dalmarcogd/bpl-go/internal/models"
 	"github.com/dalmarcogd/bpl-go/internal/services"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// uniqueViolation is the postgres SQLSTATE of a duplicate key.
+const uniqueViolation = "23505"
+
 type (
 	ServiceImpl struct {
+		services.NoopHealth
 		serviceManager services.Sis
 		ctx            context.Context
 	}
@@ -36,44 +44,75 @@ func (s *ServiceImpl) Sis() services.Sis {
 	return s.serviceManager
 }
 
+// logger returns the request scoped logger, or the root one outside requests.
+func (s *ServiceImpl) logger(ctx context.Context) services.Logger {
+	if log := ctxs.GetLoggerFromContext(ctx); log != nil {
+		return log
+	}
+	return s.Sis().Logger()
+}
+
 func (s *ServiceImpl) CreateUser(ctx context.Context, user *models.User) error {
 	user.Id = uuid.New().String()
-	result := s.Sis().Database().DB(ctx).Create(&user)
+	result := s.Sis().Database().DB(ctx).Create(user)
 	if result.Error != nil {
-		return result.Error
+		return userError(result.Error)
 	}
+	s.logger(ctx).Info(ctx, "User created", map[string]interface{}{"user_id": user.Id})
 	return nil
 }
 
+// UpdateUser updates the name and email of the user of u.Id.
 func (s *ServiceImpl) UpdateUser(ctx context.Context, u *models.User) error {
-	result := s.Sis().Database().DB(ctx).Save(&u)
+	result := s.Sis().Database().DB(ctx).Model(&models.User{}).Where("id = ?", u.Id).Updates(map[string]interface{}{
+		"name":  u.Name,
+		"email": u.Email,
+	})
 	if result.Error != nil {
-		return result.Error
+		return userError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.UserNotFound
 	}
+	s.logger(ctx).Info(ctx, "User updated", map[string]interface{}{"user_id": u.Id})
 	return nil
 }
 
 func (s *ServiceImpl) GetUser(ctx context.Context, u *models.User) error {
-	result := s.Sis().Database().DB(ctx).Find(&u)
+	result := s.Sis().Database().DB(ctx).Where("id = ?", u.Id).First(u)
 	if result.Error != nil {
-		return result.Error
+		return userError(result.Error)
 	}
 	return nil
 }
 
 func (s *ServiceImpl) GetUsers(ctx context.Context, u *[]models.User) error {
-	result := s.Sis().Database().DB(ctx).Find(&u)
+	result := s.Sis().Database().DB(ctx).Find(u)
 	if result.Error != nil {
-		return result.Error
+		return userError(result.Error)
 	}
 	return nil
 }
 
 func (s *ServiceImpl) DeleteUser(ctx context.Context, u *models.User) error {
-	result := s.Sis().Database().DB(ctx).Delete(&u)
+	result := s.Sis().Database().DB(ctx).Where("id = ?", u.Id).Delete(u)
 	if result.Error != nil {
-		return result.Error
+		return userError(result.Error)
 	}
-
+	if result.RowsAffected == 0 {
+		return errors.UserNotFound
+	}
+	s.logger(ctx).Info(ctx, "User deleted", map[string]interface{}{"user_id": u.Id})
 	return nil
 }
+
+func userError(err error) error {
+	if goerrors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.UserNotFound.Wrap(err)
+	}
+	var state interface{ SQLState() string }
+	if goerrors.As(err, &state) && state.SQLState() == uniqueViolation {
+		return errors.UserAlreadyExists.Wrap(err)
+	}
+	return errors.InternalError.Wrap(err)
+}
@@ -1 +1,87 @@
 package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	goerrors "errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+// mockDatabase serves a gorm.DB over sqlmock, so the handlers run their queries against expectations.
+type mockDatabase struct {
+	services.NoopDatabase
+	db *gorm.DB
+}
+
+func (d *mockDatabase) WithSis(_ services.Sis) services.Database {
+	return d
+}
+
+func (d *mockDatabase) DB(ctx context.Context) *gorm.DB {
+	return d.db.WithContext(ctx)
+}
+
+func newMockHandlers(t *testing.T) (*ServiceImpl, sqlmock.Sqlmock) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handlers := New()
+	if err := services.New().WithDatabase(&mockDatabase{db: db}).WithHandlers(handlers).Init(); err != nil {
+		t.Fatal(err)
+	}
+	return handlers, mock
+}
+
+// anyTime matches the timestamps gorm sets.
+type anyTime struct{}
+
+func (anyTime) Match(v driver.Value) bool {
+	_, ok := v.(time.Time)
+	return ok
+}
+
+func TestServiceImpl_UpdateUser(t *testing.T) {
+	handlers, mock := newMockHandlers(t)
+	name, email := "John", "john@doe.com"
+
+	tests := []struct {
+		name    string
+		rows    int64
+		wantErr error
+	}{
+		{name: "existing", rows: 1},
+		{name: "unknown", rows: 0, wantErr: errors.UserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE "users" SET "email"=\$1,"name"=\$2,"updated_at"=\$3 WHERE id = \$4`).
+				WithArgs(email, name, anyTime{}, "u1").
+				WillReturnResult(sqlmock.NewResult(0, tt.rows))
+			mock.ExpectCommit()
+
+			err := handlers.UpdateUser(context.Background(), &models.User{Id: "u1", Name: &name, Email: &email})
+			if !goerrors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateUser() = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
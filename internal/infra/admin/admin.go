@@ -0,0 +1,107 @@
+package admin
+
+import (
+	"context"
+	goerrors "errors"
+	infrahttp "github.com/dalmarcogd/bpl-go/internal/infra/http"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/pprof"
+)
+
+type (
+	// ServiceImpl is the admin server, its listener must not be reachable from outside.
+	ServiceImpl struct {
+		services.NoopHealth
+		serviceManager services.Sis
+		ctx            context.Context
+		echo           *echo.Echo
+		server         *http.Server
+		address        string
+	}
+)
+
+// addressOff as the address disables the admin server.
+const addressOff = "off"
+
+func New() *ServiceImpl {
+	return &ServiceImpl{}
+}
+
+func (s *ServiceImpl) WithAddress(address string) *ServiceImpl {
+	s.address = address
+	return s
+}
+
+func (s *ServiceImpl) Init(ctx context.Context) error {
+	s.ctx = ctx
+	if s.address == "" {
+		s.address = s.Sis().Environment().AdminAddress()
+	}
+	env := s.Sis().Environment()
+	s.echo = infrahttp.NewEcho(s.Sis().Logger())
+	s.echo.Use(infrahttp.CidMiddleware())
+	s.echo.Use(infrahttp.RecoverMiddleware(s.Sis().Logger()))
+	s.RegisterRoutes()
+
+	s.server = &http.Server{
+		Addr:              s.address,
+		Handler:           s.echo,
+		ReadHeaderTimeout: env.HttpReadHeaderTimeout(),
+		ReadTimeout:       env.HttpReadTimeout(),
+		WriteTimeout:      env.HttpWriteTimeout(),
+		IdleTimeout:       env.HttpIdleTimeout(),
+		MaxHeaderBytes:    env.HttpMaxHeaderBytes(),
+		ErrorLog:          s.echo.StdLogger,
+	}
+	if env.DebugPprof() {
+		// net/http/pprof refuses profiles and traces longer than the write timeout
+		s.server.WriteTimeout = 0
+	}
+	return nil
+}
+
+func (s *ServiceImpl) Close() error {
+	return s.server.Shutdown(s.ctx)
+}
+
+func (s *ServiceImpl) WithSis(c services.Sis) services.AdminServer {
+	s.serviceManager = c
+	return s
+}
+
+func (s *ServiceImpl) Sis() services.Sis {
+	return s.serviceManager
+}
+
+func (s *ServiceImpl) RegisterRoutes() *ServiceImpl {
+	s.echo.GET("/health", s.handleGetHealth)
+	s.echo.GET("/metrics", echo.WrapHandler(s.Sis().Metrics().Handler()))
+	s.echo.GET("/config", s.handleGetConfig)
+	s.echo.GET("/build", s.handleGetBuild)
+	s.echo.GET("/routes", s.handleGetRoutes)
+	s.echo.GET("/log-levels", s.handleGetLogLevels)
+	s.echo.PUT("/log-levels", s.handleSetLogLevel)
+
+	if s.Sis().Environment().DebugPprof() {
+		s.echo.GET("/debug/pprof/cmdline", echo.WrapHandler(http.HandlerFunc(pprof.Cmdline)))
+		s.echo.GET("/debug/pprof/profile", echo.WrapHandler(http.HandlerFunc(pprof.Profile)))
+		s.echo.Match([]string{http.MethodGet, http.MethodPost}, "/debug/pprof/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+		s.echo.GET("/debug/pprof/trace", echo.WrapHandler(http.HandlerFunc(pprof.Trace)))
+		// the index serves the named profiles too, as /debug/pprof/heap
+		s.echo.GET("/debug/pprof/*", echo.WrapHandler(http.HandlerFunc(pprof.Index)))
+	}
+	return s
+}
+
+// Run serves until Close, the address off disables the admin server.
+func (s *ServiceImpl) Run() error {
+	if s.address == addressOff {
+		return nil
+	}
+	if err := s.server.ListenAndServe(); err != nil && !goerrors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type pprofEnvironment struct {
+	services.NoopEnvironment
+	pprof bool
+}
+
+func (e *pprofEnvironment) WithSis(_ services.Sis) services.Environment {
+	return e
+}
+
+func (e *pprofEnvironment) DebugPprof() bool {
+	return e.pprof
+}
+
+func (e *pprofEnvironment) HttpReadTimeout() time.Duration {
+	return time.Second
+}
+
+func (e *pprofEnvironment) HttpWriteTimeout() time.Duration {
+	return 2 * time.Second
+}
+
+func TestServiceImpl_Routes(t *testing.T) {
+	tests := []struct {
+		name   string
+		pprof  bool
+		path   string
+		status int
+	}{
+		{name: "health", path: "/health", status: http.StatusOK},
+		{name: "config", path: "/config", status: http.StatusOK},
+		{name: "build", path: "/build", status: http.StatusOK},
+		{name: "routes", path: "/routes", status: http.StatusOK},
+		{name: "log levels", path: "/log-levels", status: http.StatusOK},
+		{name: "pprof disabled", path: "/debug/pprof/", status: http.StatusNotFound},
+		{name: "pprof", pprof: true, path: "/debug/pprof/", status: http.StatusOK},
+		{name: "pprof profile", pprof: true, path: "/debug/pprof/goroutine?debug=1", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			services.New().WithEnvironment(&pprofEnvironment{pprof: tt.pprof}).WithAdminServer(s)
+			if err := s.Init(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			s.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Errorf("GET %v = %d, want %d", tt.path, rec.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestServiceImpl_handleGetHealth(t *testing.T) {
+	s := New()
+	services.New().WithAdminServer(s)
+	if err := s.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	health := new(models.HealthResponse)
+	if err := json.Unmarshal(rec.Body.Bytes(), health); err != nil {
+		t.Fatal(err)
+	}
+	if health.Status != healthStatusOk {
+		t.Errorf("health = %+v, want %v", health, healthStatusOk)
+	}
+}
+
+func TestServiceImpl_Server(t *testing.T) {
+	tests := []struct {
+		name         string
+		pprof        bool
+		writeTimeout time.Duration
+	}{
+		{name: "timeouts", writeTimeout: 2 * time.Second},
+		{name: "pprof without write timeout", pprof: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			services.New().WithEnvironment(&pprofEnvironment{pprof: tt.pprof}).WithAdminServer(s)
+			if err := s.Init(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if s.server.ReadTimeout != time.Second || s.server.WriteTimeout != tt.writeTimeout {
+				t.Errorf("timeouts = %v %v, want %v %v", s.server.ReadTimeout, s.server.WriteTimeout, time.Second, tt.writeTimeout)
+			}
+		})
+	}
+}
+
+func TestServiceImpl_Recover(t *testing.T) {
+	s := New()
+	services.New().WithAdminServer(s)
+	if err := s.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	s.echo.GET("/panic", func(echo.Context) error {
+		panic("admin handler failed")
+	})
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("GET /panic = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestServiceImpl_RunOff(t *testing.T) {
+	s := New().WithAddress(addressOff)
+	services.New().WithAdminServer(s)
+	if err := s.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Run(); err != nil {
+		t.Errorf("Run() = %v, want nil", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Close() = %v, want nil", err)
+	}
+}
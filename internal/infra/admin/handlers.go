@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+const (
+	healthStatusOk          = "ok"
+	healthStatusUnavailable = "unavailable"
+)
+
+func (s *ServiceImpl) handleGetHealth(c echo.Context) error {
+	if err := s.Sis().Health(c.Request().Context()); err != nil {
+		message := err.Error()
+		return c.JSON(http.StatusServiceUnavailable, &models.HealthResponse{Status: healthStatusUnavailable, Error: &message})
+	}
+	return c.JSON(http.StatusOK, &models.HealthResponse{Status: healthStatusOk})
+}
+
+func (s *ServiceImpl) handleGetConfig(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.Sis().Environment().Redacted())
+}
+
+func (s *ServiceImpl) handleGetBuild(c echo.Context) error {
+	env := s.Sis().Environment()
+	build := &models.BuildInfoResponse{
+		Service:     env.Service(),
+		Version:     env.Version(),
+		Environment: env.Environment(),
+		GoVersion:   runtime.Version(),
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		build.Module = info.Main.Path
+		build.ModuleVersion = info.Main.Version
+	}
+	return c.JSON(http.StatusOK, build)
+}
+
+func (s *ServiceImpl) handleGetRoutes(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.Sis().HttpServer().Routes())
+}
+
+func (s *ServiceImpl) handleGetLogLevels(c echo.Context) error {
+	log := s.Sis().Logger()
+	return c.JSON(http.StatusOK, &models.LogLevelsResponse{
+		Level:    log.Level(),
+		Packages: log.PackageLevels(),
+		Dropped:  log.Dropped(),
+	})
+}
+
+func (s *ServiceImpl) handleSetLogLevel(c echo.Context) error {
+	lReq := new(models.LogLevelRequest)
+	if err := c.Bind(lReq); err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error()).SetInternal(err)
+	}
+	level := ""
+	if lReq.Level != nil {
+		level = *lReq.Level
+	}
+
+	log := s.Sis().Logger()
+	var err error
+	if lReq.Package != nil && *lReq.Package != "" {
+		err = log.SetPackageLevel(*lReq.Package, level)
+	} else {
+		err = log.SetLevel(level)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error()).SetInternal(err)
+	}
+	log.Info(c.Request().Context(), "Log levels changed", map[string]interface{}{
+		"level":    log.Level(),
+		"packages": log.PackageLevels(),
+	})
+
+	return s.handleGetLogLevels(c)
+}
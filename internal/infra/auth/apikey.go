@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"strings"
+)
+
+// ApiKeySubjectPrefix prefixes the id of an api key on the subject of its principal.
+const ApiKeySubjectPrefix = "api-key:"
+
+type (
+	// ApiKeyAuthenticator authenticates the api keys of the Handlers, their scopes are the roles.
+	ApiKeyAuthenticator struct {
+		handlers services.Handlers
+	}
+)
+
+func NewApiKeyAuthenticator(handlers services.Handlers) *ApiKeyAuthenticator {
+	return &ApiKeyAuthenticator{handlers: handlers}
+}
+
+func (a *ApiKeyAuthenticator) Authenticate(ctx context.Context, key string) (*structs.Principal, error) {
+	apiKey, err := a.handlers.AuthenticateApiKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil {
+		return nil, errors.AuthenticationInvalid
+	}
+	principal := &structs.Principal{
+		Subject: ApiKeySubjectPrefix + apiKey.Id,
+		Roles:   strings.Fields(apiKey.Scopes),
+	}
+	if apiKey.Tenant != nil {
+		principal.Tenant = *apiKey.Tenant
+	}
+	return principal, nil
+}
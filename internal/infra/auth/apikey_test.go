@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"reflect"
+	"testing"
+)
+
+type apiKeyHandlers struct {
+	services.NoopHandlers
+	keys map[string]*models.ApiKey
+}
+
+func (h *apiKeyHandlers) AuthenticateApiKey(_ context.Context, plain string) (*models.ApiKey, error) {
+	return h.keys[plain], nil
+}
+
+func TestApiKeyAuthenticator_Authenticate(t *testing.T) {
+	tenant := "acme"
+	authenticator := NewApiKeyAuthenticator(&apiKeyHandlers{keys: map[string]*models.ApiKey{
+		"bpl_a1_secret": {Id: "key-1", Scopes: "users:read users:write", Tenant: &tenant},
+	}})
+
+	principal, err := authenticator.Authenticate(context.Background(), "bpl_a1_secret")
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if principal.Subject != "api-key:key-1" || principal.Tenant != tenant || !reflect.DeepEqual(principal.Roles, []string{"users:read", "users:write"}) {
+		t.Errorf("Authenticate() = %+v", principal)
+	}
+	if _, err := authenticator.Authenticate(context.Background(), "bpl_a2_other"); err == nil {
+		t.Error("Authenticate() of an unknown key succeeded")
+	}
+}
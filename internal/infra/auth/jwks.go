@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"golang.org/x/sync/singleflight"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksMinRefreshInterval bounds how often an unknown kid fetches the endpoint again.
+	jwksMinRefreshInterval = 10 * time.Second
+	jwksFetchTimeout       = 10 * time.Second
+	jwksMaxSize            = 1 << 20
+	jwksRefreshKey         = "jwks"
+)
+
+type (
+	// JWK is a verification key of a JWKS.
+	JWK struct {
+		Kid string
+		Alg string
+		Key interface{}
+	}
+	// KeySet finds the key a token was signed with by its kid.
+	KeySet interface {
+		Key(ctx context.Context, kid string) (*JWK, error)
+	}
+	// StaticKeySet is a KeySet loaded once.
+	StaticKeySet struct {
+		keys map[string]*JWK
+	}
+	// HTTPKeySet is a KeySet fetched from a JWKS endpoint and cached for ttl, an unknown kid fetches it
+	// again. Concurrent callers wait for a single fetch, detached from their cancellation, and the cached
+	// keys are kept when the endpoint fails.
+	HTTPKeySet struct {
+		url       string
+		ttl       time.Duration
+		client    *http.Client
+		refreshes singleflight.Group
+		mu        sync.Mutex
+		keys      map[string]*JWK
+		fetchedAt time.Time
+		checkedAt time.Time
+	}
+	jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Alg string `json:"alg"`
+		Use string `json:"use"`
+		Crv string `json:"crv"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+		K   string `json:"k"`
+	}
+)
+
+// NewFileKeySet loads the JWKS of the file at path.
+func NewFileKeySet(path string) (*StaticKeySet, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := ParseJWKS(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &StaticKeySet{keys: keys}, nil
+}
+
+func (s *StaticKeySet) Key(_ context.Context, kid string) (*JWK, error) {
+	return lookupKey(s.keys, kid)
+}
+
+func NewHTTPKeySet(url string, ttl time.Duration) *HTTPKeySet {
+	return &HTTPKeySet{
+		url:    url,
+		ttl:    ttl,
+		client: &http.Client{Timeout: jwksFetchTimeout},
+	}
+}
+
+func (s *HTTPKeySet) WithClient(client *http.Client) *HTTPKeySet {
+	s.client = client
+	return s
+}
+
+// WithTransport sends the requests of the endpoint through transport.
+func (s *HTTPKeySet) WithTransport(transport http.RoundTripper) *HTTPKeySet {
+	s.client = &http.Client{Timeout: jwksFetchTimeout, Transport: transport}
+	return s
+}
+
+func (s *HTTPKeySet) Key(ctx context.Context, kid string) (*JWK, error) {
+	s.mu.Lock()
+	keys, fresh := s.keys, time.Since(s.fetchedAt) < s.ttl
+	s.mu.Unlock()
+	if key, err := lookupKey(keys, kid); fresh && err == nil {
+		return key, nil
+	}
+
+	refreshed := s.refreshes.DoChan(jwksRefreshKey, func() (interface{}, error) {
+		return s.refresh(ctxs.Detach(ctx))
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-refreshed:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return lookupKey(result.Val.(map[string]*JWK), kid)
+	}
+}
+
+// refresh fetches the keys at most once per jwksMinRefreshInterval, without holding the lock.
+func (s *HTTPKeySet) refresh(ctx context.Context) (map[string]*JWK, error) {
+	s.mu.Lock()
+	now := time.Now()
+	if now.Sub(s.checkedAt) < jwksMinRefreshInterval {
+		defer s.mu.Unlock()
+		return s.keys, nil
+	}
+	s.checkedAt = now
+	s.mu.Unlock()
+
+	keys, err := s.fetch(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		if s.keys == nil {
+			return nil, err
+		}
+		return s.keys, nil
+	}
+	s.keys = keys
+	s.fetchedAt = now
+	return keys, nil
+}
+
+func (s *HTTPKeySet) fetch(ctx context.Context) (map[string]*JWK, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %v answered %v", errors.AuthJWKSInvalid, s.url, resp.StatusCode)
+	}
+	raw, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, jwksMaxSize))
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(raw)
+}
+
+// ParseJWKS decodes the RSA, EC P-256 and oct keys of a JWKS by kid.
+func ParseJWKS(raw []byte) (map[string]*JWK, error) {
+	set := new(jwks)
+	if err := json.Unmarshal(raw, set); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.AuthJWKSInvalid, err)
+	}
+	keys := make(map[string]*JWK, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.key()
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q: %v", errors.AuthJWKSInvalid, k.Kid, err)
+		}
+		if key == nil {
+			continue
+		}
+		keys[k.Kid] = &JWK{Kid: k.Kid, Alg: k.Alg, Key: key}
+	}
+	return keys, nil
+}
+
+func (k jwk) key() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, fmt.Errorf("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func lookupKey(keys map[string]*JWK, kid string) (*JWK, error) {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, errors.AuthJWKNotFound
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/golang-jwt/jwt/v4"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultRolesClaim  = "roles"
+	DefaultTenantClaim = "tenant"
+)
+
+type (
+	// Authenticator resolves the principal of the credentials given on an Authorization scheme.
+	Authenticator interface {
+		Authenticate(ctx context.Context, credentials string) (*structs.Principal, error)
+	}
+	// JWTAuthenticator verifies RS256, ES256 and HS256 tokens with the keys of a KeySet.
+	JWTAuthenticator struct {
+		keys        KeySet
+		issuer      string
+		audience    string
+		rolesClaim  string
+		tenantClaim string
+		parser      *jwt.Parser
+	}
+)
+
+func NewJWTAuthenticator(keys KeySet) *JWTAuthenticator {
+	return &JWTAuthenticator{
+		keys:        keys,
+		rolesClaim:  DefaultRolesClaim,
+		tenantClaim: DefaultTenantClaim,
+		parser: &jwt.Parser{ValidMethods: []string{
+			jwt.SigningMethodRS256.Alg(),
+			jwt.SigningMethodES256.Alg(),
+			jwt.SigningMethodHS256.Alg(),
+		}},
+	}
+}
+
+// WithIssuer requires the iss claim to be issuer.
+func (a *JWTAuthenticator) WithIssuer(issuer string) *JWTAuthenticator {
+	a.issuer = issuer
+	return a
+}
+
+// WithAudience requires the aud claim to contain audience.
+func (a *JWTAuthenticator) WithAudience(audience string) *JWTAuthenticator {
+	a.audience = audience
+	return a
+}
+
+func (a *JWTAuthenticator) WithRolesClaim(claim string) *JWTAuthenticator {
+	a.rolesClaim = claim
+	return a
+}
+
+func (a *JWTAuthenticator) WithTenantClaim(claim string) *JWTAuthenticator {
+	a.tenantClaim = claim
+	return a
+}
+
+// Authenticate verifies the signature, expiration, issuer and audience of token.
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (*structs.Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := a.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := a.keys.Key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		// a key is only used with its own algorithm, so a public key can not verify an HMAC
+		if key.Alg != "" && key.Alg != t.Method.Alg() {
+			return nil, errors.AuthJWKAlgMismatch
+		}
+		return key.Key, nil
+	})
+	if err != nil {
+		return nil, errors.AuthenticationInvalid.Wrap(err)
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errors.AuthenticationInvalid.Wrap(fmt.Errorf("token has no exp"))
+	}
+	if a.issuer != "" && !claims.VerifyIssuer(a.issuer, true) {
+		return nil, errors.AuthenticationInvalid.Wrap(fmt.Errorf("token iss is not %v", a.issuer))
+	}
+	if a.audience != "" && !claims.VerifyAudience(a.audience, true) {
+		return nil, errors.AuthenticationInvalid.Wrap(fmt.Errorf("token aud has no %v", a.audience))
+	}
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, errors.AuthenticationInvalid.Wrap(fmt.Errorf("token has no sub"))
+	}
+	tenant, _ := claim(claims, a.tenantClaim).(string)
+	return &structs.Principal{
+		Subject: subject,
+		Roles:   roles(claim(claims, a.rolesClaim)),
+		Tenant:  tenant,
+	}, nil
+}
+
+// claim returns the claim at the dotted path, as realm_access.roles.
+func claim(claims map[string]interface{}, path string) interface{} {
+	var value interface{} = claims
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+	return value
+}
+
+func roles(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		roles := make([]string, 0, len(v))
+		for _, role := range v {
+			if s, ok := role.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+		return roles
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	goerrors "errors"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/golang-jwt/jwt/v4"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testKeys struct {
+	rsa    *rsa.PrivateKey
+	ec     *ecdsa.PrivateKey
+	secret []byte
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testKeys{rsa: rsaKey, ec: ecKey, secret: []byte("0123456789abcdef0123456789abcdef")}
+}
+
+func (k *testKeys) jwks() []byte {
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	raw, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "alg": "RS256", "use": "sig", "n": b64(k.rsa.N.Bytes()), "e": b64(big.NewInt(int64(k.rsa.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(k.ec.X.Bytes()), "y": b64(k.ec.Y.Bytes())},
+		{"kty": "oct", "kid": "hmac", "alg": "HS256", "k": b64(k.secret)},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": b64(k.rsa.N.Bytes()), "e": "AQAB"},
+	}})
+	return raw
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestJWTAuthenticator_Authenticate(t *testing.T) {
+	keys := newTestKeys(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := ioutil.WriteFile(path, keys.jwks(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	keySet, err := NewFileKeySet(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator := NewJWTAuthenticator(keySet).
+		WithIssuer("https://issuer").
+		WithAudience("bpl").
+		WithRolesClaim("realm_access.roles")
+
+	claims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"sub":          "user-1",
+			"iss":          "https://issuer",
+			"aud":          []string{"bpl", "other"},
+			"exp":          time.Now().Add(time.Minute).Unix(),
+			"tenant":       "acme",
+			"realm_access": map[string]interface{}{"roles": []string{"users:read"}},
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+				continue
+			}
+			c[k] = v
+		}
+		return c
+	}
+	publicRSA, _ := json.Marshal(keys.rsa.PublicKey)
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{name: "rs256", token: sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, claims(nil)), valid: true},
+		{name: "es256", token: sign(t, jwt.SigningMethodES256, "ec", keys.ec, claims(nil)), valid: true},
+		{name: "hs256", token: sign(t, jwt.SigningMethodHS256, "hmac", keys.secret, claims(nil)), valid: true},
+		{name: "expired", token: sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, claims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}))},
+		{name: "without exp", token: sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, claims(jwt.MapClaims{"exp": nil}))},
+		{name: "without sub", token: sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, claims(jwt.MapClaims{"sub": nil}))},
+		{name: "other issuer", token: sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, claims(jwt.MapClaims{"iss": "https://evil"}))},
+		{name: "other audience", token: sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, claims(jwt.MapClaims{"aud": "other"}))},
+		{name: "unknown kid", token: sign(t, jwt.SigningMethodRS256, "other", keys.rsa, claims(nil))},
+		{name: "encryption key", token: sign(t, jwt.SigningMethodRS256, "enc", keys.rsa, claims(nil))},
+		{name: "hmac with the public key", token: sign(t, jwt.SigningMethodHS256, "rsa", publicRSA, claims(nil))},
+		{name: "unsupported algorithm", token: sign(t, jwt.SigningMethodHS512, "hmac", keys.secret, claims(nil))},
+		{name: "malformed", token: "not.a.token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := authenticator.Authenticate(context.Background(), tt.token)
+			if !tt.valid {
+				if !goerrors.Is(err, errors.AuthenticationInvalid) {
+					t.Errorf("Authenticate() error = %v, want %v", err, errors.AuthenticationInvalid)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if principal.Subject != "user-1" || principal.Tenant != "acme" || !reflect.DeepEqual(principal.Roles, []string{"users:read"}) {
+				t.Errorf("Authenticate() = %+v", principal)
+			}
+		})
+	}
+}
+
+func TestHTTPKeySet_Key(t *testing.T) {
+	keys := newTestKeys(t)
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		_, _ = w.Write(keys.jwks())
+	}))
+	keySet := NewHTTPKeySet(server.URL, time.Hour)
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := keySet.Key(ctx, "rsa"); err != nil {
+			t.Fatalf("Key() error = %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetches = %v, want the keys cached", fetches)
+	}
+
+	// unknown kids fetch again only after jwksMinRefreshInterval
+	if _, err := keySet.Key(ctx, "rotated"); !goerrors.Is(err, errors.AuthJWKNotFound) {
+		t.Errorf("Key() error = %v, want %v", err, errors.AuthJWKNotFound)
+	}
+	if fetches != 1 {
+		t.Errorf("fetches = %v, want 1", fetches)
+	}
+	keySet.checkedAt = time.Now().Add(-jwksMinRefreshInterval)
+	_, _ = keySet.Key(ctx, "rotated")
+	if fetches != 2 {
+		t.Errorf("fetches = %v, want 2", fetches)
+	}
+
+	// the cached keys outlive a failing endpoint
+	server.Close()
+	keySet.fetchedAt = time.Now().Add(-time.Hour)
+	keySet.checkedAt = keySet.fetchedAt
+	if _, err := keySet.Key(ctx, "ec"); err != nil {
+		t.Errorf("Key() error = %v, want the stale key", err)
+	}
+}
+
+func TestHTTPKeySet_KeyDetached(t *testing.T) {
+	keys := newTestKeys(t)
+	var fetches int32
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		started <- struct{}{}
+		<-release
+		_, _ = w.Write(keys.jwks())
+	}))
+	defer server.Close()
+	keySet := NewHTTPKeySet(server.URL, time.Hour)
+
+	// the caller that started the fetch gives up without aborting it for the others
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := keySet.Key(ctx, "rsa")
+		first <- err
+	}()
+	<-started
+	second := make(chan error, 1)
+	go func() {
+		_, err := keySet.Key(context.Background(), "ec")
+		second <- err
+	}()
+	cancel()
+	if err := <-first; !goerrors.Is(err, context.Canceled) {
+		t.Errorf("Key() of the canceled caller error = %v, want %v", err, context.Canceled)
+	}
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("Key() of the waiting caller error = %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("fetches = %v, want the concurrent callers merged", fetches)
+	}
+}
@@ -2,45 +2,155 @@ package cache
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	goerrors "errors"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
 	"github.com/dalmarcogd/bpl-go/internal/services"
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"io/ioutil"
+	"time"
+)
+
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+
+	defaultLockTTL = 30 * time.Second
+)
+
+var (
+	// acquireLockScript returns the next fencing token, or 0 when the key is taken.
+	acquireLockScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0`)
+	refreshLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
 )
 
 type (
 	ServiceImpl struct {
 		serviceManager services.Sis
 		ctx            context.Context
-		client         *redis.Client
-		address        string
+		client         redis.UniversalClient
+		options        *redis.UniversalOptions
+		mode           string
+		loads          *loadGroup
 	}
 )
 
 func New() *ServiceImpl {
-	return &ServiceImpl{}
+	s := &ServiceImpl{}
+	s.loads = newLoadGroup(s)
+	return s
 }
 
 func (s *ServiceImpl) WithAddress(address string) *ServiceImpl {
-	s.address = address
+	s.options = &redis.UniversalOptions{Addrs: []string{address}}
+	s.mode = ModeStandalone
+	return s
+}
+
+// WithOptions bypasses the Environment configuration.
+func (s *ServiceImpl) WithOptions(mode string, options *redis.UniversalOptions) *ServiceImpl {
+	s.options = options
+	s.mode = mode
 	return s
 }
 
 func (s *ServiceImpl) Init(ctx context.Context) error {
 	s.ctx = ctx
-	s.address = s.Sis().Environment().CacheAddress()
-	c := redis.NewClient(&redis.Options{
-		Addr:     s.address,
-		DB:       0,
-		Password: "",
-	})
+	redis.SetLogger(newRedisLogger(s.Sis().Logger()))
+	if s.options == nil {
+		options, err := s.optionsFromEnvironment()
+		if err != nil {
+			return err
+		}
+		s.options = options
+		s.mode = s.Sis().Environment().CacheMode()
+	}
+
+	var c redis.UniversalClient
+	switch s.mode {
+	case ModeStandalone, "":
+		c = redis.NewClient(s.options.Simple())
+	case ModeSentinel:
+		c = redis.NewFailoverClient(s.options.Failover())
+	case ModeCluster:
+		c = redis.NewClusterClient(s.options.Cluster())
+	default:
+		return errors.CacheModeUnsupported
+	}
 	_, err := c.Ping(s.ctx).Result()
 	if err != nil {
+		_ = c.Close()
 		return err
 	}
 	s.client = c
+	s.loads.init(s.ctx)
+	registerMetrics(s.Sis().Metrics(), s.client)
 	return nil
 }
 
+func (s *ServiceImpl) optionsFromEnvironment() (*redis.UniversalOptions, error) {
+	env := s.Sis().Environment()
+	options := &redis.UniversalOptions{
+		Addrs:            env.CacheAddresses(),
+		DB:               env.CacheDB(),
+		Username:         env.CacheUsername(),
+		Password:         env.CachePassword(),
+		MasterName:       env.CacheMasterName(),
+		SentinelPassword: env.CacheSentinelPassword(),
+		DialTimeout:      env.CacheDialTimeout(),
+		ReadTimeout:      env.CacheReadTimeout(),
+		WriteTimeout:     env.CacheWriteTimeout(),
+		PoolSize:         env.CachePoolSize(),
+		MinIdleConns:     env.CacheMinIdleConns(),
+	}
+	if env.CacheMode() == ModeSentinel && options.MasterName == "" {
+		return nil, errors.CacheMasterNameRequired
+	}
+	if env.CacheTLS() {
+		tlsConfig := &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ServerName: env.CacheTLSServerName(),
+		}
+		if caFile := env.CacheTLSCAFile(); caFile != "" {
+			pem, err := ioutil.ReadFile(caFile)
+			if err != nil {
+				return nil, err
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, errors.CacheTLSCAInvalid
+			}
+			tlsConfig.RootCAs = pool
+		}
+		options.TLSConfig = tlsConfig
+	}
+	return options, nil
+}
+
+func (s *ServiceImpl) Health(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
 func (s *ServiceImpl) Close() error {
+	if s.client == nil {
+		return nil
+	}
 	return s.client.Close()
 }
 
@@ -52,3 +162,102 @@ func (s *ServiceImpl) WithSis(c services.Sis) services.Cache {
 func (s *ServiceImpl) Sis() services.Sis {
 	return s.serviceManager
 }
+
+func (s *ServiceImpl) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.Get(ctx, key).Bytes()
+	if goerrors.Is(err, redis.Nil) {
+		return nil, errors.CacheKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (s *ServiceImpl) getWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	var get *redis.StringCmd
+	var pttl *redis.DurationCmd
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+	if goerrors.Is(err, redis.Nil) {
+		return nil, 0, errors.CacheKeyNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	value, err := get.Bytes()
+	if err != nil {
+		return nil, 0, err
+	}
+	ttl := pttl.Val()
+	if ttl < 0 {
+		ttl = 0
+	}
+	return value, ttl, nil
+}
+
+func (s *ServiceImpl) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s *ServiceImpl) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
+
+// GetOrLoad returns the value of key, calling loader on a miss.
+func (s *ServiceImpl) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader services.CacheLoader) ([]byte, error) {
+	return s.loads.getOrLoad(ctx, key, ttl, loader)
+}
+
+// Lock acquires a lease on key, renewed in background until it is released.
+func (s *ServiceImpl) Lock(ctx context.Context, key string, ttl time.Duration) (services.Lock, error) {
+	if ttl <= 0 {
+		ttl = defaultLockTTL
+	}
+	token := uuid.New().String()
+	fencing, err := acquireLockScript.Run(
+		ctx,
+		s.client,
+		[]string{lockKey(key), lockKey(key) + ":fencing"},
+		token,
+		ttl.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return nil, err
+	}
+	if fencing == 0 {
+		return nil, errors.CacheLockNotAcquired
+	}
+	return newLock(s, key, token, fencing, ttl), nil
+}
+
+func (s *ServiceImpl) refreshLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	refreshed, err := refreshLockScript.Run(ctx, s.client, []string{lockKey(key)}, token, ttl.Milliseconds()).Int64()
+	return refreshed == 1, err
+}
+
+func (s *ServiceImpl) releaseLock(ctx context.Context, key, token string) (bool, error) {
+	released, err := releaseLockScript.Run(ctx, s.client, []string{lockKey(key)}, token).Int64()
+	return released == 1, err
+}
+
+// lockKey uses a hash tag so the lock and its fencing counter live on the same cluster slot.
+func lockKey(key string) string {
+	return "lock:{" + key + "}"
+}
+
+// Publish sends message to every subscriber of channel.
+func (s *ServiceImpl) Publish(ctx context.Context, channel string, message string) error {
+	return s.client.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe listens to channel, the returned PubSub must be closed by the caller.
+func (s *ServiceImpl) Subscribe(ctx context.Context, channel string) *redis.PubSub {
+	return s.client.Subscribe(ctx, channel)
+}
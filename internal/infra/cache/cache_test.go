@@ -1 +1,199 @@
 package cache
+
+import (
+	"context"
+	goerrors "errors"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRUServiceImpl(t *testing.T) {
+	serviceImpl := NewLRU().WithSize(2)
+	sm := services.New().WithCache(serviceImpl)
+	if err := sm.Init(); err != nil {
+		t.Error(err)
+	}
+
+	ctx := context.Background()
+	_ = serviceImpl.Set(ctx, "a", []byte("1"), 0)
+	_ = serviceImpl.Set(ctx, "b", []byte("2"), 0)
+	if _, err := serviceImpl.Get(ctx, "a"); err != nil {
+		t.Error(err)
+	}
+	_ = serviceImpl.Set(ctx, "c", []byte("3"), 0)
+	if _, err := serviceImpl.Get(ctx, "b"); !goerrors.Is(err, errors.CacheKeyNotFound) {
+		t.Errorf("expected b to be evicted, got %v", err)
+	}
+	if v, err := serviceImpl.Get(ctx, "a"); err != nil || string(v) != "1" {
+		t.Errorf("Get() = %s, %v, want 1", v, err)
+	}
+
+	_ = serviceImpl.Set(ctx, "d", []byte("4"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, err := serviceImpl.Get(ctx, "d"); !goerrors.Is(err, errors.CacheKeyNotFound) {
+		t.Errorf("expected d to be expired, got %v", err)
+	}
+
+	_ = serviceImpl.Delete(ctx, "a", "c")
+	if serviceImpl.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", serviceImpl.Len())
+	}
+
+	if err := sm.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLRUServiceImpl_Lock(t *testing.T) {
+	serviceImpl := NewLRU()
+	ctx := context.Background()
+
+	first, err := serviceImpl.Lock(ctx, "job", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := serviceImpl.Lock(ctx, "job", time.Second); !goerrors.Is(err, errors.CacheLockNotAcquired) {
+		t.Errorf("expected lock to be held, got %v", err)
+	}
+	if err := first.Release(ctx); err != nil {
+		t.Error(err)
+	}
+	if err := first.Release(ctx); !goerrors.Is(err, errors.CacheLockNotHeld) {
+		t.Errorf("expected double release to fail, got %v", err)
+	}
+
+	second, err := serviceImpl.Lock(ctx, "job", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.FencingToken() <= first.FencingToken() {
+		t.Errorf("FencingToken() = %d, want greater than %d", second.FencingToken(), first.FencingToken())
+	}
+	_ = second.Release(ctx)
+}
+
+func TestLRUServiceImpl_LockTinyTTL(t *testing.T) {
+	serviceImpl := NewLRU()
+	ctx := context.Background()
+	for _, ttl := range []time.Duration{time.Nanosecond, 2 * time.Nanosecond} {
+		l, err := serviceImpl.Lock(ctx, "job", ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the renewal comes after the lease expired
+		select {
+		case <-l.Lost():
+		case <-time.After(time.Second):
+			t.Errorf("lock of ttl %v not lost", ttl)
+		}
+		_ = l.Release(ctx)
+	}
+}
+
+type staleEnvironment struct {
+	services.NoopEnvironment
+}
+
+func (e *staleEnvironment) WithSis(_ services.Sis) services.Environment {
+	return e
+}
+
+func (e *staleEnvironment) CacheStaleTTL() time.Duration {
+	return time.Hour
+}
+
+func TestLRUServiceImpl_GetOrLoad(t *testing.T) {
+	serviceImpl := NewLRU()
+	sm := services.New().WithCache(serviceImpl).WithEnvironment(&staleEnvironment{})
+	if err := sm.Init(); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	serviceImpl.loads.now = func() time.Time {
+		return now
+	}
+	ctx := context.Background()
+
+	var loads int32
+	started, release, loaded := make(chan struct{}, 3), make(chan struct{}), make(chan struct{}, 3)
+	loader := func(ctx context.Context) ([]byte, error) {
+		atomic.AddInt32(&loads, 1)
+		started <- struct{}{}
+		<-release
+		loaded <- struct{}{}
+		return []byte("value"), ctx.Err()
+	}
+
+	// the first caller gives up, the load keeps going for the ones merged with it
+	cancelled, cancel := context.WithCancel(ctx)
+	errs := make(chan error, 1)
+	go func() {
+		_, err := serviceImpl.GetOrLoad(cancelled, "key", time.Minute, loader)
+		errs <- err
+	}()
+	<-started
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := serviceImpl.GetOrLoad(ctx, "key", time.Minute, loader); err != nil || string(v) != "value" {
+				t.Errorf("GetOrLoad() = %s, %v, want value", v, err)
+			}
+		}()
+	}
+	cancel()
+	if err := <-errs; !goerrors.Is(err, context.Canceled) {
+		t.Errorf("cancelled GetOrLoad() error = %v, want %v", err, context.Canceled)
+	}
+	close(release)
+	wg.Wait()
+	<-loaded
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Errorf("loader called %d times, want 1", n)
+	}
+
+	// past the fresh ttl the value is served while it is refreshed in background
+	now = now.Add(2 * time.Minute)
+	if v, err := serviceImpl.GetOrLoad(ctx, "key", time.Minute, loader); err != nil || string(v) != "value" {
+		t.Errorf("GetOrLoad() = %s, %v, want stale value", v, err)
+	}
+	select {
+	case <-loaded:
+	case <-time.After(time.Second):
+		t.Fatal("value was not refreshed in background")
+	}
+
+	// entries of GetOrLoad do not mix with the values of Set
+	_ = serviceImpl.Set(ctx, "plain", []byte("0123456789"), 0)
+	if v, err := serviceImpl.GetOrLoad(ctx, "plain", time.Minute, loader); err != nil || string(v) != "value" {
+		t.Errorf("GetOrLoad() = %s, %v, want the loaded value", v, err)
+	}
+	<-loaded
+	if v, err := serviceImpl.Get(ctx, "plain"); err != nil || string(v) != "0123456789" {
+		t.Errorf("Get() = %s, %v, want the value of Set", v, err)
+	}
+}
+
+func TestLRUServiceImpl_RateLimit(t *testing.T) {
+	serviceImpl := NewLRU()
+	ctx := context.Background()
+
+	for _, algorithm := range []string{structs.RateLimitTokenBucket, structs.RateLimitSlidingWindow} {
+		limit := structs.RateLimit{Algorithm: algorithm, Limit: 2, Period: time.Minute}
+		for i := 0; i < 2; i++ {
+			if result, err := serviceImpl.RateLimit(ctx, algorithm, limit); err != nil || !result.Allowed {
+				t.Errorf("%v request %d = %+v, %v, want allowed", algorithm, i, result, err)
+			}
+		}
+		result, err := serviceImpl.RateLimit(ctx, algorithm, limit)
+		if err != nil || result.Allowed || result.Remaining != 0 || result.RetryAfter <= 0 {
+			t.Errorf("%v request 3 = %+v, %v, want denied", algorithm, result, err)
+		}
+	}
+}
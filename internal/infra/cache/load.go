@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	goerrors "errors"
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"golang.org/x/sync/singleflight"
+	"math/rand"
+	"time"
+)
+
+const (
+	entryHeaderSize    = 8
+	defaultLoadTimeout = 30 * time.Second
+	// loadKeyPrefix keeps the entries of GetOrLoad apart from the values of Set.
+	loadKeyPrefix = "load:"
+)
+
+type (
+	// loadGroup serves stale values for staleTTL while a single caller refreshes them.
+	loadGroup struct {
+		cache       services.Cache
+		ctx         context.Context
+		group       singleflight.Group
+		now         func() time.Time
+		staleTTL    time.Duration
+		jitter      float64
+		refreshLock bool
+	}
+)
+
+func newLoadGroup(cache services.Cache) *loadGroup {
+	return &loadGroup{cache: cache, ctx: context.Background(), now: time.Now}
+}
+
+func (g *loadGroup) init(ctx context.Context) {
+	g.ctx = ctx
+	if sis := g.cache.Sis(); sis != nil {
+		env := sis.Environment()
+		g.staleTTL = env.CacheStaleTTL()
+		g.jitter = env.CacheTTLJitter()
+		g.refreshLock = env.CacheRefreshLock()
+	}
+}
+
+// getOrLoad merges the concurrent loads of key. The load runs detached from the callers, each caller
+// stops waiting when its own ctx is done.
+func (g *loadGroup) getOrLoad(ctx context.Context, key string, ttl time.Duration, loader services.CacheLoader) ([]byte, error) {
+	raw, err := g.cache.Get(ctx, loadKey(key))
+	if err == nil {
+		if freshUntil, value, ok := decodeEntry(raw); ok {
+			if !freshUntil.IsZero() && g.now().After(freshUntil) {
+				g.refresh(key, ttl, loader)
+			}
+			return value, nil
+		}
+	} else if !goerrors.Is(err, errors.CacheKeyNotFound) {
+		g.logError(ctx, fmt.Sprintf("Cache get of %v failed, loading from source: %v", key, err))
+	}
+
+	loaded := g.group.DoChan("load:"+key, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(ctxs.Detach(ctx), defaultLoadTimeout)
+		defer cancel()
+		return g.load(loadCtx, key, ttl, loader)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-loaded:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.([]byte), nil
+	}
+}
+
+// refresh reloads key in background, only on the holder of the lock with refreshLock.
+func (g *loadGroup) refresh(key string, ttl time.Duration, loader services.CacheLoader) {
+	g.group.DoChan("refresh:"+key, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(g.ctx, defaultLoadTimeout)
+		defer cancel()
+		if g.refreshLock {
+			lock, err := g.cache.Lock(ctx, "refresh:"+key, defaultLoadTimeout)
+			if err != nil {
+				return nil, err
+			}
+			defer func() {
+				_ = lock.Release(ctx)
+			}()
+		}
+		value, err := g.load(ctx, key, ttl, loader)
+		if err != nil {
+			g.logError(ctx, fmt.Sprintf("Cache refresh of %v failed: %v", key, err))
+		}
+		return value, err
+	})
+}
+
+func (g *loadGroup) load(ctx context.Context, key string, ttl time.Duration, loader services.CacheLoader) ([]byte, error) {
+	value, err := loader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var freshUntil time.Time
+	var storeTTL time.Duration
+	if fresh := g.withJitter(ttl); fresh > 0 {
+		freshUntil = g.now().Add(fresh)
+		storeTTL = fresh + g.staleTTL
+	}
+	if err := g.cache.Set(ctx, loadKey(key), encodeEntry(freshUntil, value), storeTTL); err != nil {
+		g.logError(ctx, fmt.Sprintf("Cache set of %v failed: %v", key, err))
+	}
+	return value, nil
+}
+
+func (g *loadGroup) withJitter(ttl time.Duration) time.Duration {
+	if g.jitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+	delta := time.Duration((rand.Float64()*2 - 1) * g.jitter * float64(ttl))
+	return ttl + delta
+}
+
+func (g *loadGroup) logError(ctx context.Context, message string) {
+	if sis := g.cache.Sis(); sis != nil {
+		sis.Logger().Error(ctx, message)
+	}
+}
+
+func loadKey(key string) string {
+	return loadKeyPrefix + key
+}
+
+// encodeEntry prefixes value with the unix nano instant it stops being fresh.
+func encodeEntry(freshUntil time.Time, value []byte) []byte {
+	raw := make([]byte, entryHeaderSize+len(value))
+	if !freshUntil.IsZero() {
+		binary.BigEndian.PutUint64(raw, uint64(freshUntil.UnixNano()))
+	}
+	copy(raw[entryHeaderSize:], value)
+	return raw
+}
+
+func decodeEntry(raw []byte) (time.Time, []byte, bool) {
+	if len(raw) < entryHeaderSize {
+		return time.Time{}, nil, false
+	}
+	var freshUntil time.Time
+	if nanos := int64(binary.BigEndian.Uint64(raw)); nanos != 0 {
+		freshUntil = time.Unix(0, nanos)
+	}
+	return freshUntil, raw[entryHeaderSize:], true
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"sync"
+	"time"
+)
+
+// minLockRenewInterval keeps the ticker of tiny ttls positive.
+const minLockRenewInterval = time.Millisecond
+
+type (
+	lockBackend interface {
+		refreshLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+		releaseLock(ctx context.Context, key, token string) (bool, error)
+	}
+	lock struct {
+		backend  lockBackend
+		key      string
+		token    string
+		fencing  int64
+		ttl      time.Duration
+		lost     chan struct{}
+		lostOnce sync.Once
+		cancel   context.CancelFunc
+		done     chan struct{}
+	}
+)
+
+func newLock(backend lockBackend, key, token string, fencing int64, ttl time.Duration) *lock {
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &lock{
+		backend: backend,
+		key:     key,
+		token:   token,
+		fencing: fencing,
+		ttl:     ttl,
+		lost:    make(chan struct{}),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	go l.renew(ctx)
+	return l
+}
+
+func (l *lock) Key() string {
+	return l.key
+}
+
+func (l *lock) Token() string {
+	return l.token
+}
+
+func (l *lock) FencingToken() int64 {
+	return l.fencing
+}
+
+func (l *lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *lock) Release(ctx context.Context) error {
+	l.cancel()
+	<-l.done
+	released, err := l.backend.releaseLock(ctx, l.key, l.token)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return errors.CacheLockNotHeld
+	}
+	return nil
+}
+
+func (l *lock) renew(ctx context.Context) {
+	defer close(l.done)
+	interval := l.ttl / 3
+	if interval < minLockRenewInterval {
+		interval = minLockRenewInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refreshed, err := l.backend.refreshLock(ctx, l.key, l.token, l.ttl)
+			if err == nil && refreshed {
+				renewedAt = time.Now()
+				continue
+			}
+			if err == nil || time.Since(renewedAt) >= l.ttl {
+				l.lostOnce.Do(func() { close(l.lost) })
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+const defaultLRUSize = 10000
+
+type (
+	lruEntry struct {
+		key       string
+		value     []byte
+		expiresAt time.Time
+	}
+	lruLock struct {
+		token     string
+		expiresAt time.Time
+	}
+	// LRUServiceImpl is an in-process cache evicting the least recently used entries.
+	LRUServiceImpl struct {
+		services.NoopHealth
+		serviceManager services.Sis
+		ctx            context.Context
+		mu             sync.Mutex
+		size           int
+		items          map[string]*list.Element
+		order          *list.List
+		locksMu        sync.Mutex
+		locks          map[string]*lruLock
+		fencing        map[string]int64
+		loads          *loadGroup
+		limiter        *memoryRateLimiter
+	}
+)
+
+func NewLRU() *LRUServiceImpl {
+	s := &LRUServiceImpl{
+		size:    defaultLRUSize,
+		items:   map[string]*list.Element{},
+		order:   list.New(),
+		locks:   map[string]*lruLock{},
+		fencing: map[string]int64{},
+		limiter: newMemoryRateLimiter(),
+	}
+	s.loads = newLoadGroup(s)
+	return s
+}
+
+func (s *LRUServiceImpl) WithSize(size int) *LRUServiceImpl {
+	s.size = size
+	return s
+}
+
+func (s *LRUServiceImpl) Init(ctx context.Context) error {
+	s.ctx = ctx
+	s.loads.init(s.ctx)
+	return nil
+}
+
+func (s *LRUServiceImpl) Close() error {
+	s.Purge()
+	return nil
+}
+
+func (s *LRUServiceImpl) WithSis(c services.Sis) services.Cache {
+	s.serviceManager = c
+	return s
+}
+
+func (s *LRUServiceImpl) Sis() services.Sis {
+	return s.serviceManager
+}
+
+func (s *LRUServiceImpl) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.items[key]
+	if !ok {
+		return nil, errors.CacheKeyNotFound
+	}
+	entry := el.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		s.removeElement(el)
+		return nil, errors.CacheKeyNotFound
+	}
+	s.order.MoveToFront(el)
+	return entry.value, nil
+}
+
+// Set stores value under key, a ttl of zero or less never expires.
+func (s *LRUServiceImpl) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		s.order.MoveToFront(el)
+		return nil
+	}
+	s.items[key] = s.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for s.size > 0 && s.order.Len() > s.size {
+		s.removeElement(s.order.Back())
+	}
+	return nil
+}
+
+func (s *LRUServiceImpl) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		if el, ok := s.items[key]; ok {
+			s.removeElement(el)
+		}
+	}
+	return nil
+}
+
+func (s *LRUServiceImpl) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader services.CacheLoader) ([]byte, error) {
+	return s.loads.getOrLoad(ctx, key, ttl, loader)
+}
+
+// Lock acquires an in-process lease on key.
+func (s *LRUServiceImpl) Lock(_ context.Context, key string, ttl time.Duration) (services.Lock, error) {
+	if ttl <= 0 {
+		ttl = defaultLockTTL
+	}
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+	if l, ok := s.locks[key]; ok && time.Now().Before(l.expiresAt) {
+		return nil, errors.CacheLockNotAcquired
+	}
+	token := uuid.New().String()
+	s.locks[key] = &lruLock{token: token, expiresAt: time.Now().Add(ttl)}
+	s.fencing[key]++
+	return newLock(s, key, token, s.fencing[key], ttl), nil
+}
+
+func (s *LRUServiceImpl) refreshLock(_ context.Context, key, token string, ttl time.Duration) (bool, error) {
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+	l, ok := s.locks[key]
+	if !ok || l.token != token || time.Now().After(l.expiresAt) {
+		return false, nil
+	}
+	l.expiresAt = time.Now().Add(ttl)
+	return true, nil
+}
+
+func (s *LRUServiceImpl) releaseLock(_ context.Context, key, token string) (bool, error) {
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+	l, ok := s.locks[key]
+	if !ok || l.token != token || time.Now().After(l.expiresAt) {
+		return false, nil
+	}
+	delete(s.locks, key)
+	return true, nil
+}
+
+// RateLimit consumes one request of key, counting only this process.
+func (s *LRUServiceImpl) RateLimit(_ context.Context, key string, limit structs.RateLimit) (*structs.RateLimitResult, error) {
+	return s.limiter.rateLimit(key, limit)
+}
+
+// Len returns the number of entries, including the expired ones not evicted yet.
+func (s *LRUServiceImpl) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// Purge removes all entries.
+func (s *LRUServiceImpl) Purge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = map[string]*list.Element{}
+	s.order.Init()
+}
+
+func (s *LRUServiceImpl) removeElement(el *list.Element) {
+	s.order.Remove(el)
+	delete(s.items, el.Value.(*lruEntry).key)
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/go-redis/redis/v8"
+)
+
+// registerMetrics exposes the redis.PoolStats of client, summed over the nodes of a cluster.
+func registerMetrics(metrics services.Metrics, client redis.UniversalClient) {
+	pool, ok := client.(interface{ PoolStats() *redis.PoolStats })
+	if !ok {
+		return
+	}
+	stat := func(f func(stats *redis.PoolStats) float64) func() float64 {
+		return func() float64 {
+			return f(pool.PoolStats())
+		}
+	}
+	metrics.CounterFunc("cache_pool_hits_total", "Free connections found in the pool.", stat(func(s *redis.PoolStats) float64 {
+		return float64(s.Hits)
+	}))
+	metrics.CounterFunc("cache_pool_misses_total", "Free connections not found in the pool.", stat(func(s *redis.PoolStats) float64 {
+		return float64(s.Misses)
+	}))
+	metrics.CounterFunc("cache_pool_timeouts_total", "Waits for a connection that timed out.", stat(func(s *redis.PoolStats) float64 {
+		return float64(s.Timeouts)
+	}))
+	metrics.CounterFunc("cache_pool_stale_connections_total", "Stale connections removed from the pool.", stat(func(s *redis.PoolStats) float64 {
+		return float64(s.StaleConns)
+	}))
+	metrics.GaugeFunc("cache_pool_connections", "Connections in the pool.", stat(func(s *redis.PoolStats) float64 {
+		return float64(s.TotalConns)
+	}))
+	metrics.GaugeFunc("cache_pool_idle_connections", "Idle connections in the pool.", stat(func(s *redis.PoolStats) float64 {
+		return float64(s.IdleConns)
+	}))
+}
@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const memoryRateLimiterSweepSize = 10000
+
+var (
+	// tokenBucketScript returns {allowed, remaining, retry after ms, reset after ms}.
+	tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+local rate = capacity / period
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], period)
+return {allowed, math.floor(tokens), retry, math.ceil((capacity - tokens) / rate)}`)
+	// slidingWindowScript returns {allowed, remaining, retry after ms, reset after ms}.
+	slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call("ZADD", KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call("PEXPIRE", KEYS[1], window)
+local reset = 0
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+local retry = 0
+if allowed == 0 then
+	retry = reset
+end
+return {allowed, limit - count, retry, reset}`)
+)
+
+type (
+	tokenBucket struct {
+		tokens    float64
+		updatedAt time.Time
+		period    time.Duration
+	}
+	slidingWindow struct {
+		requests []time.Time
+		period   time.Duration
+	}
+	// memoryRateLimiter applies the algorithms of the Redis scripts in a single process.
+	memoryRateLimiter struct {
+		mu      sync.Mutex
+		buckets map[string]*tokenBucket
+		windows map[string]*slidingWindow
+	}
+)
+
+// RateLimit consumes one request of key, shared by every instance of the Redis.
+func (s *ServiceImpl) RateLimit(ctx context.Context, key string, limit structs.RateLimit) (*structs.RateLimitResult, error) {
+	if err := limit.Validate(); err != nil {
+		return nil, err
+	}
+	var script *redis.Script
+	args := []interface{}{limit.Limit, limit.Period.Milliseconds(), time.Now().UnixNano() / int64(time.Millisecond)}
+	switch limit.Algorithm {
+	case structs.RateLimitTokenBucket, "":
+		script = tokenBucketScript
+	case structs.RateLimitSlidingWindow:
+		script = slidingWindowScript
+		args = append(args, uuid.New().String())
+	default:
+		return nil, errors.RateLimitAlgorithmUnsupported
+	}
+	values, err := script.Run(ctx, s.client, []string{"ratelimit:" + key}, args...).Result()
+	if err != nil {
+		return nil, err
+	}
+	reply, ok := values.([]interface{})
+	if !ok || len(reply) != 4 {
+		return nil, errors.RateLimitReplyInvalid
+	}
+	numbers := make([]int64, len(reply))
+	for i, v := range reply {
+		if numbers[i], ok = v.(int64); !ok {
+			return nil, errors.RateLimitReplyInvalid
+		}
+	}
+	return &structs.RateLimitResult{
+		Allowed:    numbers[0] == 1,
+		Limit:      limit.Limit,
+		Remaining:  int(numbers[1]),
+		RetryAfter: time.Duration(numbers[2]) * time.Millisecond,
+		ResetAfter: time.Duration(numbers[3]) * time.Millisecond,
+	}, nil
+}
+
+func newMemoryRateLimiter() *memoryRateLimiter {
+	return &memoryRateLimiter{
+		buckets: map[string]*tokenBucket{},
+		windows: map[string]*slidingWindow{},
+	}
+}
+
+func (m *memoryRateLimiter) rateLimit(key string, limit structs.RateLimit) (*structs.RateLimitResult, error) {
+	if err := limit.Validate(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.buckets)+len(m.windows) > memoryRateLimiterSweepSize {
+		m.sweep(time.Now())
+	}
+	switch limit.Algorithm {
+	case structs.RateLimitTokenBucket, "":
+		return m.tokenBucket(key, limit, time.Now()), nil
+	case structs.RateLimitSlidingWindow:
+		return m.slidingWindow(key, limit, time.Now()), nil
+	default:
+		return nil, errors.RateLimitAlgorithmUnsupported
+	}
+}
+
+func (m *memoryRateLimiter) tokenBucket(key string, limit structs.RateLimit, now time.Time) *structs.RateLimitResult {
+	capacity := float64(limit.Limit)
+	rate := capacity / float64(limit.Period)
+	bucket, ok := m.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, updatedAt: now, period: limit.Period}
+		m.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+float64(now.Sub(bucket.updatedAt))*rate)
+	bucket.updatedAt = now
+
+	result := &structs.RateLimitResult{Limit: limit.Limit}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - bucket.tokens) / rate))
+	}
+	result.Remaining = int(bucket.tokens)
+	result.ResetAfter = time.Duration(math.Ceil((capacity - bucket.tokens) / rate))
+	return result
+}
+
+func (m *memoryRateLimiter) slidingWindow(key string, limit structs.RateLimit, now time.Time) *structs.RateLimitResult {
+	window, ok := m.windows[key]
+	if !ok {
+		window = &slidingWindow{period: limit.Period}
+		m.windows[key] = window
+	}
+	start := sort.Search(len(window.requests), func(i int) bool {
+		return window.requests[i].After(now.Add(-limit.Period))
+	})
+	requests := window.requests[start:]
+
+	result := &structs.RateLimitResult{Limit: limit.Limit}
+	if len(requests) < limit.Limit {
+		requests = append(requests, now)
+		result.Allowed = true
+	}
+	window.requests = requests
+	result.Remaining = limit.Limit - len(requests)
+	if len(requests) > 0 {
+		result.ResetAfter = requests[0].Add(limit.Period).Sub(now)
+	}
+	if !result.Allowed {
+		result.RetryAfter = result.ResetAfter
+	}
+	return result
+}
+
+func (m *memoryRateLimiter) sweep(now time.Time) {
+	for key, bucket := range m.buckets {
+		if now.Sub(bucket.updatedAt) >= bucket.period {
+			delete(m.buckets, key)
+		}
+	}
+	for key, window := range m.windows {
+		if len(window.requests) == 0 || now.Sub(window.requests[len(window.requests)-1]) >= window.period {
+			delete(m.windows, key)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+)
+
+// redisLogger logs the connection and pool failures of go-redis at Warn.
+type redisLogger struct {
+	log services.Logger
+}
+
+func newRedisLogger(l services.Logger) *redisLogger {
+	return &redisLogger{log: l.With(map[string]interface{}{"component": "redis"}).WithCallerSkip(1)}
+}
+
+func (l *redisLogger) Printf(ctx context.Context, format string, v ...interface{}) {
+	l.log.Warn(ctx, fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/dalmarcogd/bpl-go/internal/infra/logger"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"testing"
+)
+
+func TestRedisLogger(t *testing.T) {
+	formatter, _ := logger.NewFormatter(logger.FormatJSON)
+	out := new(bytes.Buffer)
+	serviceImpl := logger.New().WithSink(logger.NewSink("buffer", out, logrus.TraceLevel, formatter))
+	sm := services.New().WithLogger(serviceImpl)
+	if err := sm.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = sm.Close()
+	}()
+	out.Reset()
+
+	newRedisLogger(serviceImpl).Printf(context.Background(), "redis: discarding bad PubSub connection: %v", "EOF")
+	entry := map[string]interface{}{}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["level"] != "warning" || entry["text"] != "redis: discarding bad PubSub connection: EOF" || entry["component"] != "redis" {
+		t.Errorf("entry = %v, want a warning of redis", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.Contains(caller, "cache.TestRedisLogger") {
+		t.Errorf("caller = %v, want the caller of the adapter", caller)
+	}
+}
@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultInvalidationChannel = "cache:invalidations"
+	defaultL1MaxTTL            = time.Minute
+	invalidationSeparator      = "|"
+)
+
+type (
+	// TwoTierServiceImpl keeps a LRUServiceImpl (L1) in front of a ServiceImpl (L2), invalidated over pub/sub.
+	TwoTierServiceImpl struct {
+		serviceManager services.Sis
+		ctx            context.Context
+		cancel         context.CancelFunc
+		wg             sync.WaitGroup
+		l1             *LRUServiceImpl
+		l2             *ServiceImpl
+		l1MaxTTL       time.Duration
+		channel        string
+		instanceId     string
+		loads          *loadGroup
+	}
+)
+
+func NewTwoTier(l2 *ServiceImpl) *TwoTierServiceImpl {
+	s := &TwoTierServiceImpl{
+		l1:         NewLRU(),
+		l2:         l2,
+		l1MaxTTL:   defaultL1MaxTTL,
+		channel:    defaultInvalidationChannel,
+		instanceId: uuid.New().String(),
+	}
+	s.loads = newLoadGroup(s)
+	return s
+}
+
+func (s *TwoTierServiceImpl) WithL1(l1 *LRUServiceImpl) *TwoTierServiceImpl {
+	s.l1 = l1
+	return s
+}
+
+// WithL1MaxTTL bounds how long an entry can live in L1.
+func (s *TwoTierServiceImpl) WithL1MaxTTL(ttl time.Duration) *TwoTierServiceImpl {
+	s.l1MaxTTL = ttl
+	return s
+}
+
+func (s *TwoTierServiceImpl) WithChannel(channel string) *TwoTierServiceImpl {
+	s.channel = channel
+	return s
+}
+
+func (s *TwoTierServiceImpl) Init(ctx context.Context) error {
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	if err := s.l1.Init(s.ctx); err != nil {
+		return err
+	}
+	if err := s.l2.Init(s.ctx); err != nil {
+		return err
+	}
+
+	pubSub := s.l2.Subscribe(s.ctx, s.channel)
+	if _, err := pubSub.Receive(s.ctx); err != nil {
+		_ = pubSub.Close()
+		return err
+	}
+	s.wg.Add(1)
+	go s.listenInvalidations(pubSub.Channel())
+	go func() {
+		<-s.ctx.Done()
+		_ = pubSub.Close()
+	}()
+	s.loads.init(s.ctx)
+	return nil
+}
+
+func (s *TwoTierServiceImpl) Health(ctx context.Context) error {
+	return s.l2.Health(ctx)
+}
+
+func (s *TwoTierServiceImpl) Close() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	if err := s.l1.Close(); err != nil {
+		return err
+	}
+	return s.l2.Close()
+}
+
+func (s *TwoTierServiceImpl) WithSis(c services.Sis) services.Cache {
+	s.serviceManager = c
+	s.l1.WithSis(c)
+	s.l2.WithSis(c)
+	return s
+}
+
+func (s *TwoTierServiceImpl) Sis() services.Sis {
+	return s.serviceManager
+}
+
+func (s *TwoTierServiceImpl) Get(ctx context.Context, key string) ([]byte, error) {
+	if value, err := s.l1.Get(ctx, key); err == nil {
+		return value, nil
+	}
+	value, ttl, err := s.l2.getWithTTL(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.l1.Set(ctx, key, value, s.l1TTL(ttl))
+	return value, nil
+}
+
+func (s *TwoTierServiceImpl) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := s.l2.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	if err := s.invalidate(ctx, key); err != nil {
+		return err
+	}
+	return s.l1.Set(ctx, key, value, s.l1TTL(ttl))
+}
+
+func (s *TwoTierServiceImpl) Delete(ctx context.Context, keys ...string) error {
+	if err := s.l1.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	if err := s.l2.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	return s.invalidate(ctx, keys...)
+}
+
+func (s *TwoTierServiceImpl) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader services.CacheLoader) ([]byte, error) {
+	return s.loads.getOrLoad(ctx, key, ttl, loader)
+}
+
+func (s *TwoTierServiceImpl) Lock(ctx context.Context, key string, ttl time.Duration) (services.Lock, error) {
+	return s.l2.Lock(ctx, key, ttl)
+}
+
+func (s *TwoTierServiceImpl) RateLimit(ctx context.Context, key string, limit structs.RateLimit) (*structs.RateLimitResult, error) {
+	return s.l2.RateLimit(ctx, key, limit)
+}
+
+// l1TTL caps l1MaxTTL at the time the entry has left in L2.
+func (s *TwoTierServiceImpl) l1TTL(ttl time.Duration) time.Duration {
+	if ttl > 0 && ttl < s.l1MaxTTL {
+		return ttl
+	}
+	return s.l1MaxTTL
+}
+
+func (s *TwoTierServiceImpl) invalidate(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := s.l2.Publish(ctx, s.channel, s.instanceId+invalidationSeparator+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TwoTierServiceImpl) listenInvalidations(messages <-chan *redis.Message) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			parts := strings.SplitN(msg.Payload, invalidationSeparator, 2)
+			if len(parts) != 2 || parts[0] == s.instanceId {
+				continue
+			}
+			_ = s.l1.Delete(s.ctx, parts[1])
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	goerrors "errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"testing"
+	"time"
+)
+
+func runRedis(t *testing.T) *miniredis.Miniredis {
+	redis, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(redis.Close)
+	return redis
+}
+
+func newTestTwoTier(t *testing.T, redis *miniredis.Miniredis) *TwoTierServiceImpl {
+	serviceImpl := NewTwoTier(New().WithAddress(redis.Addr()))
+	sm := services.New().WithCache(serviceImpl)
+	if err := sm.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = sm.Close()
+	})
+	return serviceImpl
+}
+
+func TestTwoTierServiceImpl(t *testing.T) {
+	redis := runRedis(t)
+	first := newTestTwoTier(t, redis)
+	second := newTestTwoTier(t, redis)
+	ctx := context.Background()
+
+	if err := first.Set(ctx, "key", []byte("1"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := second.Get(ctx, "key"); err != nil || string(v) != "1" {
+		t.Fatalf("Get() = %s, %v, want 1", v, err)
+	}
+	if v, err := second.l1.Get(ctx, "key"); err != nil || string(v) != "1" {
+		t.Errorf("L1 Get() = %s, %v, want the L2 hit cached", v, err)
+	}
+
+	// the write of first evicts the L1 copy of second
+	if err := first.Set(ctx, "key", []byte("2"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := second.l1.Get(ctx, "key"); goerrors.Is(err, errors.CacheKeyNotFound) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("L1 of second was not invalidated")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if v, err := second.Get(ctx, "key"); err != nil || string(v) != "2" {
+		t.Errorf("Get() = %s, %v, want 2", v, err)
+	}
+
+	if err := first.Delete(ctx, "key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.Get(ctx, "key"); !goerrors.Is(err, errors.CacheKeyNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, errors.CacheKeyNotFound)
+	}
+}
+
+func TestTwoTierServiceImpl_GetCapsL1TTL(t *testing.T) {
+	redis := runRedis(t)
+	serviceImpl := newTestTwoTier(t, redis)
+	ctx := context.Background()
+
+	if err := serviceImpl.l2.Set(ctx, "short", []byte("1"), 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := serviceImpl.Get(ctx, "short"); err != nil {
+		t.Fatal(err)
+	}
+	el, ok := serviceImpl.l1.items["short"]
+	if !ok {
+		t.Fatal("L2 hit not cached in L1")
+	}
+	if left := time.Until(el.Value.(*lruEntry).expiresAt); left > 2*time.Second {
+		t.Errorf("L1 ttl = %v, want at most the 2s left in L2", left)
+	}
+}
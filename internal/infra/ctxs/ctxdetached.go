@@ -0,0 +1,27 @@
+package ctxs
+
+import (
+	"context"
+	"time"
+)
+
+type detachedContext struct {
+	context.Context
+}
+
+// Detach returns a context with the values of ctx that is never canceled.
+func Detach(ctx context.Context) context.Context {
+	return detachedContext{ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
@@ -0,0 +1,23 @@
+package ctxs
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDetach(t *testing.T) {
+	ctx, cancel := context.WithTimeout(ContextWithCid(context.Background(), "test-1"), time.Minute)
+	cancel()
+
+	detached := Detach(ctx)
+	if detached.Err() != nil || detached.Done() != nil {
+		t.Errorf("Err(), Done() = %v, %v, want the cancellation dropped", detached.Err(), detached.Done())
+	}
+	if _, ok := detached.Deadline(); ok {
+		t.Error("Deadline() ok, want the deadline dropped")
+	}
+	if cid := GetCidFromContext(detached); cid == nil || *cid != "test-1" {
+		t.Errorf("cid = %v, want the values kept", cid)
+	}
+}
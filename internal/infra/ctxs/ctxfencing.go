@@ -0,0 +1,19 @@
+package ctxs
+
+import "context"
+
+const (
+	xFencingTokenKey = "xFencingTokenKey"
+)
+
+func ContextWithFencingToken(ctx context.Context, token int64) context.Context {
+	return context.WithValue(ctx, xFencingTokenKey, token)
+}
+
+func GetFencingTokenFromContext(ctx context.Context) int64 {
+	value := ctx.Value(xFencingTokenKey)
+	if token, ok := value.(int64); ok {
+		return token
+	}
+	return 0
+}
@@ -0,0 +1,22 @@
+package ctxs
+
+import (
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+)
+
+const (
+	xLoggerKey = "xLoggerKey"
+)
+
+func ContextWithLogger(ctx context.Context, log services.Logger) context.Context {
+	return context.WithValue(ctx, xLoggerKey, log)
+}
+
+func GetLoggerFromContext(ctx context.Context) services.Logger {
+	value := ctx.Value(xLoggerKey)
+	if log, ok := value.(services.Logger); ok {
+		return log
+	}
+	return nil
+}
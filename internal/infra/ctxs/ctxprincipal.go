@@ -0,0 +1,22 @@
+package ctxs
+
+import (
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+)
+
+const (
+	xPrincipalKey = "xPrincipalKey"
+)
+
+func ContextWithPrincipal(ctx context.Context, principal *structs.Principal) context.Context {
+	return context.WithValue(ctx, xPrincipalKey, principal)
+}
+
+func GetPrincipalFromContext(ctx context.Context) *structs.Principal {
+	value := ctx.Value(xPrincipalKey)
+	if principal, ok := value.(*structs.Principal); ok {
+		return principal
+	}
+	return nil
+}
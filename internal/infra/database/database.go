@@ -31,6 +31,7 @@ func (s *ServiceImpl) Init(ctx context.Context) error {
 	s.dsn = s.Sis().Environment().DatabaseDsn()
 	c, err := gorm.Open(postgres.Open(s.dsn), &gorm.Config{
 		PrepareStmt: true,
+		Logger:      newGormLogger(s.Sis().Logger()),
 	})
 	if err != nil {
 		return err
@@ -46,9 +47,18 @@ func (s *ServiceImpl) Init(ctx context.Context) error {
 	db.SetMaxIdleConns(1)
 	db.SetMaxOpenConns(10)
 	db.SetConnMaxLifetime(time.Hour)
+	registerMetrics(s.Sis().Metrics(), db)
 	return nil
 }
 
+func (s *ServiceImpl) Health(ctx context.Context) error {
+	db, err := s.client.DB()
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
+
 func (s *ServiceImpl) Close() error {
 	db, err := s.client.DB()
 	if err != nil {
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"time"
+)
+
+const defaultSlowThreshold = 200 * time.Millisecond
+
+// gormLogger logs the queries at Debug, the slow ones at Warn and the failed ones at Error.
+type gormLogger struct {
+	log           services.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+func newGormLogger(l services.Logger) *gormLogger {
+	return &gormLogger{
+		log:           l.With(map[string]interface{}{"component": "gorm"}).WithCallerSkip(1),
+		level:         gormlogger.Info,
+		slowThreshold: defaultSlowThreshold,
+	}
+}
+
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	c := *l
+	c.level = level
+	return &c
+}
+
+func (l *gormLogger) Info(ctx context.Context, format string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.log.Info(ctx, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, format string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.log.Warn(ctx, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, format string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.log.Error(ctx, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	fields := map[string]interface{}{
+		"sql":        sql,
+		"rows":       rows,
+		"elapsed_ms": float64(elapsed.Microseconds()) / 1000,
+	}
+	switch {
+	case err != nil && !goerrors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		fields["error"] = err
+		l.log.Error(ctx, "Query failed", fields)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		l.log.Warn(ctx, "Slow query", fields)
+	case l.level >= gormlogger.Info:
+		l.log.Debug(ctx, "Query", fields)
+	}
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/logger"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGormLogger(t *testing.T) {
+	formatter, _ := logger.NewFormatter(logger.FormatJSON)
+	out := new(bytes.Buffer)
+	serviceImpl := logger.New().WithSink(logger.NewSink("buffer", out, logrus.TraceLevel, formatter))
+	sm := services.New().WithLogger(serviceImpl)
+	if err := sm.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = sm.Close()
+	}()
+	if err := serviceImpl.SetLevel("trace"); err != nil {
+		t.Fatal(err)
+	}
+	l := newGormLogger(serviceImpl)
+	query := func() (string, int64) {
+		return "SELECT * FROM users", 1
+	}
+
+	tests := []struct {
+		name  string
+		log   func(l gormlogger.Interface)
+		mode  gormlogger.LogLevel
+		level string
+		text  string
+	}{
+		{name: "query", log: func(l gormlogger.Interface) { l.Trace(context.Background(), time.Now(), query, nil) }, mode: gormlogger.Info, level: "debug", text: "Query"},
+		{name: "not found", log: func(l gormlogger.Interface) { l.Trace(context.Background(), time.Now(), query, gorm.ErrRecordNotFound) }, mode: gormlogger.Info, level: "debug", text: "Query"},
+		{name: "slow", log: func(l gormlogger.Interface) { l.Trace(context.Background(), time.Now().Add(-time.Second), query, nil) }, mode: gormlogger.Warn, level: "warning", text: "Slow query"},
+		{name: "failed", log: func(l gormlogger.Interface) {
+			l.Trace(context.Background(), time.Now(), query, goerrors.New("syntax error"))
+		}, mode: gormlogger.Error, level: "error", text: "Query failed"},
+		{name: "query below the mode", log: func(l gormlogger.Interface) { l.Trace(context.Background(), time.Now(), query, nil) }, mode: gormlogger.Warn},
+		{name: "silent", log: func(l gormlogger.Interface) { l.Error(context.Background(), "failed %v", 1) }, mode: gormlogger.Silent},
+		{name: "info", log: func(l gormlogger.Interface) { l.Info(context.Background(), "migrated %v", "users") }, mode: gormlogger.Info, level: "info", text: "migrated users"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out.Reset()
+			tt.log(l.LogMode(tt.mode))
+			if tt.level == "" {
+				if out.Len() != 0 {
+					t.Errorf("entry = %v, want none", out.String())
+				}
+				return
+			}
+			entry := map[string]interface{}{}
+			if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if entry["level"] != tt.level || entry["text"] != tt.text || entry["component"] != "gorm" {
+				t.Errorf("entry = %v, want %v %q of gorm", entry, tt.level, tt.text)
+			}
+			if caller, _ := entry["caller"].(string); !strings.Contains(caller, "database.TestGormLogger") {
+				t.Errorf("caller = %v, want the caller of the adapter", caller)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"database/sql"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+)
+
+// registerMetrics exposes the sql.DBStats of the connection pool of db.
+func registerMetrics(metrics services.Metrics, db *sql.DB) {
+	stat := func(f func(stats sql.DBStats) float64) func() float64 {
+		return func() float64 {
+			return f(db.Stats())
+		}
+	}
+	metrics.GaugeFunc("db_max_open_connections", "Maximum number of open connections to the database.", stat(func(s sql.DBStats) float64 {
+		return float64(s.MaxOpenConnections)
+	}))
+	metrics.GaugeFunc("db_open_connections", "Established connections, in use and idle.", stat(func(s sql.DBStats) float64 {
+		return float64(s.OpenConnections)
+	}))
+	metrics.GaugeFunc("db_in_use_connections", "Connections currently in use.", stat(func(s sql.DBStats) float64 {
+		return float64(s.InUse)
+	}))
+	metrics.GaugeFunc("db_idle_connections", "Idle connections.", stat(func(s sql.DBStats) float64 {
+		return float64(s.Idle)
+	}))
+	metrics.CounterFunc("db_wait_count_total", "Connections waited for.", stat(func(s sql.DBStats) float64 {
+		return float64(s.WaitCount)
+	}))
+	metrics.CounterFunc("db_wait_duration_seconds_total", "Time blocked waiting for a connection.", stat(func(s sql.DBStats) float64 {
+		return s.WaitDuration.Seconds()
+	}))
+	metrics.CounterFunc("db_max_idle_closed_total", "Connections closed due to SetMaxIdleConns.", stat(func(s sql.DBStats) float64 {
+		return float64(s.MaxIdleClosed)
+	}))
+	metrics.CounterFunc("db_max_lifetime_closed_total", "Connections closed due to SetConnMaxLifetime.", stat(func(s sql.DBStats) float64 {
+		return float64(s.MaxLifetimeClosed)
+	}))
+}
@@ -2,23 +2,114 @@ package environment
 
 import (
 	"context"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/crgimenes/goconfig"
 	"github.com/dalmarcogd/bpl-go/internal/services"
 )
 
-//Environment this object keep the all variables environment
+// redactedValue replaces the values of the fields tagged redact:"true" on Redacted.
+const redactedValue = "[REDACTED]"
+
+// frameOptionsOff as HTTP_FRAME_OPTIONS leaves the header out, an empty one gets the default.
+const frameOptionsOff = "off"
+
+// Environment this object keep the all variables environment
 type (
 	environment struct {
-		Environment  string `cfg:"ENVIRONMENT" cfgDefault:"UNKNOWN" cfgRequired:"true"`
-		Service      string `cfg:"SERVICE" cfgDefault:"hsm-api" cfgRequired:"true"`
-		Version      string `cfg:"VERSION" cfgDefault:"UNKNOWN" cfgRequired:"true"`
-		DebugPprof   bool   `cfg:"DEBUG_PPROF" cfgDefault:"false" `
-		DatabaseDsn  string `cfg:"DATABASE_DSN" cfgDefault:"user=postgres password=postgres dbname=bpl host=localhost port=5432 sslmode=disable TimeZone=UTC" cfgDefault:"false" `
-		CacheAddress string `cfg:"CacheAddress" cfgDefault:"localhost:6379" cfgDefault:"false" `
+		Environment               string  `cfg:"ENVIRONMENT" cfgDefault:"UNKNOWN" cfgRequired:"true"`
+		Service                   string  `cfg:"SERVICE" cfgDefault:"hsm-api" cfgRequired:"true"`
+		Version                   string  `cfg:"VERSION" cfgDefault:"UNKNOWN" cfgRequired:"true"`
+		DebugPprof                bool    `cfg:"DEBUG_PPROF" cfgDefault:"false" `
+		HttpAddress               string  `cfg:"HTTP_ADDRESS" cfgDefault:":8080"`
+		HttpTLSCertFile           string  `cfg:"HTTP_TLS_CERT_FILE" cfgHelper:"certificate of the server, with the key it enables tls, reloaded when changed"`
+		HttpTLSKeyFile            string  `cfg:"HTTP_TLS_KEY_FILE"`
+		HttpTLSReloadIntervalMs   int     `cfg:"HTTP_TLS_RELOAD_INTERVAL_MS" cfgDefault:"10000" cfgHelper:"how often the certificate files are checked for changes"`
+		HttpTLSMinVersion         string  `cfg:"HTTP_TLS_MIN_VERSION" cfgDefault:"1.2" cfgHelper:"1.2 or 1.3"`
+		HttpTLSCiphers            string  `cfg:"HTTP_TLS_CIPHERS" cfgHelper:"comma separated tls 1.2 cipher suites, empty uses the go defaults"`
+		HttpTLSClientCAFile       string  `cfg:"HTTP_TLS_CLIENT_CA_FILE" cfgHelper:"ca bundle verifying the client certificates, enables mtls"`
+		HttpTLSClientAuth         string  `cfg:"HTTP_TLS_CLIENT_AUTH" cfgDefault:"require" cfgHelper:"require or optional client certificates on mtls"`
+		HttpHTTP2                 bool    `cfg:"HTTP_HTTP2" cfgDefault:"true" cfgHelper:"serve http/2 over tls"`
+		HttpRedirectAddress       string  `cfg:"HTTP_REDIRECT_ADDRESS" cfgHelper:"plain http address redirecting to https, empty disables it"`
+		HttpReadHeaderTimeoutMs   int     `cfg:"HTTP_READ_HEADER_TIMEOUT_MS" cfgDefault:"5000" cfgHelper:"time to read the request headers"`
+		HttpReadTimeoutMs         int     `cfg:"HTTP_READ_TIMEOUT_MS" cfgDefault:"30000" cfgHelper:"time to read the whole request"`
+		HttpWriteTimeoutMs        int     `cfg:"HTTP_WRITE_TIMEOUT_MS" cfgDefault:"30000" cfgHelper:"time to write the response, from the end of the request headers"`
+		HttpIdleTimeoutMs         int     `cfg:"HTTP_IDLE_TIMEOUT_MS" cfgDefault:"120000" cfgHelper:"time a keep alive connection waits for the next request"`
+		HttpMaxHeaderBytes        int     `cfg:"HTTP_MAX_HEADER_BYTES" cfgDefault:"1048576"`
+		HttpMaxBodyBytes          int     `cfg:"HTTP_MAX_BODY_BYTES" cfgDefault:"1048576" cfgHelper:"larger request bodies get 413, 0 disables the limit"`
+		HttpTrustedProxies        string  `cfg:"HTTP_TRUSTED_PROXIES" cfgHelper:"comma separated cidrs of the proxies whose X-Forwarded-For gives the client ip, empty uses the connection address"`
+		HttpCORSAllowOrigins      string  `cfg:"HTTP_CORS_ALLOW_ORIGINS" cfgHelper:"comma separated origins allowed by cors, * allows any without credentials, empty disables cors"`
+		HttpCORSAllowCredentials  bool    `cfg:"HTTP_CORS_ALLOW_CREDENTIALS" cfgDefault:"false"`
+		HttpCORSMaxAgeMs          int     `cfg:"HTTP_CORS_MAX_AGE_MS" cfgDefault:"600000"`
+		HttpHSTSMaxAgeMs          int     `cfg:"HTTP_HSTS_MAX_AGE_MS" cfgDefault:"31536000000" cfgHelper:"max age of strict transport security over https, 0 disables it"`
+		HttpHSTSIncludeSubdomains bool    `cfg:"HTTP_HSTS_INCLUDE_SUBDOMAINS" cfgDefault:"true"`
+		HttpFrameOptions          string  `cfg:"HTTP_FRAME_OPTIONS" cfgDefault:"DENY" cfgHelper:"X-Frame-Options, off disables it"`
+		HttpContentTypeNosniff    bool    `cfg:"HTTP_CONTENT_TYPE_NOSNIFF" cfgDefault:"true"`
+		AdminAddress              string  `cfg:"ADMIN_ADDRESS" cfgDefault:"127.0.0.1:9090" cfgHelper:"address of the admin server, keep it internal, off disables it"`
+		OpenAPIValidationEnabled  bool    `cfg:"OPENAPI_VALIDATION_ENABLED" cfgDefault:"false" cfgHelper:"validate requests against the OpenAPI document, and responses outside production"`
+		SpanUrl                   string  `cfg:"SPAN_URL" cfgHelper:"url of the zipkin collector, empty disables the reporting of spans"`
+		DatabaseDsn               string  `cfg:"DATABASE_DSN" cfgDefault:"user=postgres password=postgres dbname=bpl host=localhost port=5432 sslmode=disable TimeZone=UTC" redact:"true"`
+		CacheMode                 string  `cfg:"CACHE_MODE" cfgDefault:"standalone" cfgHelper:"standalone, sentinel or cluster"`
+		CacheAddress              string  `cfg:"CACHE_ADDRESS" cfgDefault:"localhost:6379" cfgHelper:"comma separated list of host:port"`
+		CacheUsername             string  `cfg:"CACHE_USERNAME"`
+		CachePassword             string  `cfg:"CACHE_PASSWORD" redact:"true"`
+		CacheDB                   int     `cfg:"CACHE_DB" cfgDefault:"0"`
+		CacheMasterName           string  `cfg:"CACHE_MASTER_NAME"`
+		CacheSentinelPassword     string  `cfg:"CACHE_SENTINEL_PASSWORD" redact:"true"`
+		CacheTLS                  bool    `cfg:"CACHE_TLS" cfgDefault:"false"`
+		CacheTLSCAFile            string  `cfg:"CACHE_TLS_CA_FILE"`
+		CacheTLSServerName        string  `cfg:"CACHE_TLS_SERVER_NAME"`
+		CacheDialTimeoutMs        int     `cfg:"CACHE_DIAL_TIMEOUT_MS" cfgDefault:"5000"`
+		CacheReadTimeoutMs        int     `cfg:"CACHE_READ_TIMEOUT_MS" cfgDefault:"3000"`
+		CacheWriteTimeoutMs       int     `cfg:"CACHE_WRITE_TIMEOUT_MS" cfgDefault:"3000"`
+		CachePoolSize             int     `cfg:"CACHE_POOL_SIZE" cfgDefault:"10"`
+		CacheMinIdleConns         int     `cfg:"CACHE_MIN_IDLE_CONNS" cfgDefault:"0"`
+		CacheStaleTTLMs           int     `cfg:"CACHE_STALE_TTL_MS" cfgDefault:"30000" cfgHelper:"how long an expired value is served while it is refreshed"`
+		CacheTTLJitter            float64 `cfg:"CACHE_TTL_JITTER" cfgDefault:"0.1" cfgHelper:"fraction of the ttl randomly added or removed"`
+		CacheRefreshLock          bool    `cfg:"CACHE_REFRESH_LOCK" cfgDefault:"false" cfgHelper:"coordinate refreshes across instances with a lock"`
+		RateLimitEnabled          bool    `cfg:"RATE_LIMIT_ENABLED" cfgDefault:"true"`
+		RateLimitAlgorithm        string  `cfg:"RATE_LIMIT_ALGORITHM" cfgDefault:"token_bucket" cfgHelper:"token_bucket or sliding_window"`
+		RateLimitLimit            int     `cfg:"RATE_LIMIT_LIMIT" cfgDefault:"100"`
+		RateLimitPeriodMs         int     `cfg:"RATE_LIMIT_PERIOD_MS" cfgDefault:"60000"`
+		RateLimitRoutes           string  `cfg:"RATE_LIMIT_ROUTES" cfgHelper:"comma separated METHOD /path=limit/period_ms overriding the limit of a route"`
+		RateLimitKey              string  `cfg:"RATE_LIMIT_KEY" cfgDefault:"ip" cfgHelper:"ip, principal or tenant, the authenticated ones fall back to ip on anonymous requests"`
+		AuthEnabled               bool    `cfg:"AUTH_ENABLED" cfgDefault:"false" cfgHelper:"require a bearer token on the /v1 routes"`
+		AuthJWKSFile              string  `cfg:"AUTH_JWKS_FILE" cfgHelper:"local jwks with the keys of the tokens"`
+		AuthJWKSUrl               string  `cfg:"AUTH_JWKS_URL" cfgHelper:"jwks endpoint with the keys of the tokens, used when there is no file"`
+		AuthJWKSCacheTTLMs        int     `cfg:"AUTH_JWKS_CACHE_TTL_MS" cfgDefault:"300000"`
+		AuthJWTIssuer             string  `cfg:"AUTH_JWT_ISSUER" cfgHelper:"required iss of the tokens, empty accepts any"`
+		AuthJWTAudience           string  `cfg:"AUTH_JWT_AUDIENCE" cfgHelper:"required aud of the tokens, empty accepts any"`
+		AuthRolesClaim            string  `cfg:"AUTH_ROLES_CLAIM" cfgDefault:"roles" cfgHelper:"claim with the roles, dots reach nested claims"`
+		AuthTenantClaim           string  `cfg:"AUTH_TENANT_CLAIM" cfgDefault:"tenant"`
+		AuthApiKeysEnabled        bool    `cfg:"AUTH_API_KEYS_ENABLED" cfgDefault:"true" cfgHelper:"authenticate Authorization: ApiKey requests with the stored api keys"`
+		LogLevel                  string  `cfg:"LOG_LEVEL" cfgDefault:"info" cfgHelper:"trace, debug, info, warning, error or fatal"`
+		LogPackageLevels          string  `cfg:"LOG_PACKAGE_LEVELS" cfgHelper:"comma separated list of package=level"`
+		LogStdoutLevel            string  `cfg:"LOG_STDOUT_LEVEL" cfgDefault:"trace" cfgHelper:"minimum level of the stdout sink, off disables it"`
+		LogStdoutFormat           string  `cfg:"LOG_STDOUT_FORMAT" cfgDefault:"json" cfgHelper:"json, logfmt or console"`
+		LogFilePath               string  `cfg:"LOG_FILE_PATH" cfgHelper:"file of the file sink, empty disables it"`
+		LogFileLevel              string  `cfg:"LOG_FILE_LEVEL" cfgDefault:"trace"`
+		LogFileFormat             string  `cfg:"LOG_FILE_FORMAT" cfgDefault:"json"`
+		LogFileMaxSizeMB          int     `cfg:"LOG_FILE_MAX_SIZE_MB" cfgDefault:"100"`
+		LogFileMaxAgeDays         int     `cfg:"LOG_FILE_MAX_AGE_DAYS" cfgDefault:"7"`
+		LogFileMaxBackups         int     `cfg:"LOG_FILE_MAX_BACKUPS" cfgDefault:"10"`
+		LogFileCompress           bool    `cfg:"LOG_FILE_COMPRESS" cfgDefault:"true"`
+		LogFileRotateIntervalMs   int     `cfg:"LOG_FILE_ROTATE_INTERVAL_MS" cfgDefault:"0" cfgHelper:"rotate the file on this interval besides the size, 0 disables it"`
+		LogSyslogAddress          string  `cfg:"LOG_SYSLOG_ADDRESS" cfgHelper:"unix socket of the local syslog as /dev/log, empty disables it"`
+		LogSyslogLevel            string  `cfg:"LOG_SYSLOG_LEVEL" cfgDefault:"info"`
+		LogSyslogFormat           string  `cfg:"LOG_SYSLOG_FORMAT" cfgDefault:"logfmt"`
+		LogSamplingEnabled        bool    `cfg:"LOG_SAMPLING_ENABLED" cfgDefault:"true"`
+		LogSamplingFirst          int     `cfg:"LOG_SAMPLING_FIRST" cfgDefault:"100" cfgHelper:"entries of each message and level logged per interval before sampling"`
+		LogSamplingThereafter     int     `cfg:"LOG_SAMPLING_THEREAFTER" cfgDefault:"100" cfgHelper:"after the first ones log one in this many entries"`
+		LogSamplingIntervalMs     int     `cfg:"LOG_SAMPLING_INTERVAL_MS" cfgDefault:"1000"`
+		LogRedactEnabled          bool    `cfg:"LOG_REDACT_ENABLED" cfgDefault:"true"`
+		LogRedactKeys             string  `cfg:"LOG_REDACT_KEYS" cfgDefault:"email,password,token,access_token,refresh_token,api_key,dsn,secret,client_secret,authorization" cfgHelper:"comma separated field keys masked in the logs, matched whole ignoring case"`
+		LogRedactPatterns         string  `cfg:"LOG_REDACT_PATTERNS" cfgDefault:"email,card" cfgHelper:"comma separated built-in patterns masked in the logs: email, card"`
 	}
 
 	ServiceImpl struct {
+		services.NoopHealth
 		serviceManager services.Sis
 		ctx            context.Context
 		environment    *environment
@@ -67,10 +158,367 @@ func (s *ServiceImpl) DebugPprof() bool {
 	return s.environment.DebugPprof
 }
 
+func (s *ServiceImpl) HttpAddress() string {
+	return s.environment.HttpAddress
+}
+
+func (s *ServiceImpl) HttpTLSCertFile() string {
+	return s.environment.HttpTLSCertFile
+}
+
+func (s *ServiceImpl) HttpTLSKeyFile() string {
+	return s.environment.HttpTLSKeyFile
+}
+
+func (s *ServiceImpl) HttpTLSReloadInterval() time.Duration {
+	return time.Duration(s.environment.HttpTLSReloadIntervalMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) HttpTLSMinVersion() string {
+	return s.environment.HttpTLSMinVersion
+}
+
+func (s *ServiceImpl) HttpTLSCiphers() []string {
+	return splitList(s.environment.HttpTLSCiphers)
+}
+
+func (s *ServiceImpl) HttpTLSClientCAFile() string {
+	return s.environment.HttpTLSClientCAFile
+}
+
+func (s *ServiceImpl) HttpTLSClientAuth() string {
+	return s.environment.HttpTLSClientAuth
+}
+
+func (s *ServiceImpl) HttpHTTP2() bool {
+	return s.environment.HttpHTTP2
+}
+
+func (s *ServiceImpl) HttpRedirectAddress() string {
+	return s.environment.HttpRedirectAddress
+}
+
+func (s *ServiceImpl) HttpReadHeaderTimeout() time.Duration {
+	return time.Duration(s.environment.HttpReadHeaderTimeoutMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) HttpReadTimeout() time.Duration {
+	return time.Duration(s.environment.HttpReadTimeoutMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) HttpWriteTimeout() time.Duration {
+	return time.Duration(s.environment.HttpWriteTimeoutMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) HttpIdleTimeout() time.Duration {
+	return time.Duration(s.environment.HttpIdleTimeoutMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) HttpMaxHeaderBytes() int {
+	return s.environment.HttpMaxHeaderBytes
+}
+
+func (s *ServiceImpl) HttpMaxBodyBytes() int64 {
+	return int64(s.environment.HttpMaxBodyBytes)
+}
+
+func (s *ServiceImpl) HttpTrustedProxies() []string {
+	return splitList(s.environment.HttpTrustedProxies)
+}
+
+func (s *ServiceImpl) HttpCORSAllowOrigins() []string {
+	return splitList(s.environment.HttpCORSAllowOrigins)
+}
+
+func (s *ServiceImpl) HttpCORSAllowCredentials() bool {
+	return s.environment.HttpCORSAllowCredentials
+}
+
+func (s *ServiceImpl) HttpCORSMaxAge() time.Duration {
+	return time.Duration(s.environment.HttpCORSMaxAgeMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) HttpHSTSMaxAge() time.Duration {
+	return time.Duration(s.environment.HttpHSTSMaxAgeMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) HttpHSTSIncludeSubdomains() bool {
+	return s.environment.HttpHSTSIncludeSubdomains
+}
+
+func (s *ServiceImpl) HttpFrameOptions() string {
+	if strings.EqualFold(s.environment.HttpFrameOptions, frameOptionsOff) {
+		return ""
+	}
+	return s.environment.HttpFrameOptions
+}
+
+func (s *ServiceImpl) HttpContentTypeNosniff() bool {
+	return s.environment.HttpContentTypeNosniff
+}
+
+func (s *ServiceImpl) AdminAddress() string {
+	return s.environment.AdminAddress
+}
+
+func (s *ServiceImpl) SpanUrl() string {
+	return s.environment.SpanUrl
+}
+
+func (s *ServiceImpl) OpenAPIValidationEnabled() bool {
+	return s.environment.OpenAPIValidationEnabled
+}
+
 func (s *ServiceImpl) DatabaseDsn() string {
 	return s.environment.DatabaseDsn
 }
 
-func (s *ServiceImpl) CacheAddress() string {
-	return s.environment.CacheAddress
+func (s *ServiceImpl) CacheMode() string {
+	return s.environment.CacheMode
+}
+
+func (s *ServiceImpl) CacheAddresses() []string {
+	return splitList(s.environment.CacheAddress)
+}
+
+func (s *ServiceImpl) CacheUsername() string {
+	return s.environment.CacheUsername
+}
+
+func (s *ServiceImpl) CachePassword() string {
+	return s.environment.CachePassword
+}
+
+func (s *ServiceImpl) CacheDB() int {
+	return s.environment.CacheDB
+}
+
+func (s *ServiceImpl) CacheMasterName() string {
+	return s.environment.CacheMasterName
+}
+
+func (s *ServiceImpl) CacheSentinelPassword() string {
+	return s.environment.CacheSentinelPassword
+}
+
+func (s *ServiceImpl) CacheTLS() bool {
+	return s.environment.CacheTLS
+}
+
+func (s *ServiceImpl) CacheTLSCAFile() string {
+	return s.environment.CacheTLSCAFile
+}
+
+func (s *ServiceImpl) CacheTLSServerName() string {
+	return s.environment.CacheTLSServerName
+}
+
+func (s *ServiceImpl) CacheDialTimeout() time.Duration {
+	return time.Duration(s.environment.CacheDialTimeoutMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) CacheReadTimeout() time.Duration {
+	return time.Duration(s.environment.CacheReadTimeoutMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) CacheWriteTimeout() time.Duration {
+	return time.Duration(s.environment.CacheWriteTimeoutMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) CachePoolSize() int {
+	return s.environment.CachePoolSize
+}
+
+func (s *ServiceImpl) CacheMinIdleConns() int {
+	return s.environment.CacheMinIdleConns
+}
+
+func (s *ServiceImpl) CacheStaleTTL() time.Duration {
+	return time.Duration(s.environment.CacheStaleTTLMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) CacheTTLJitter() float64 {
+	return s.environment.CacheTTLJitter
+}
+
+func (s *ServiceImpl) CacheRefreshLock() bool {
+	return s.environment.CacheRefreshLock
+}
+
+func (s *ServiceImpl) RateLimitEnabled() bool {
+	return s.environment.RateLimitEnabled
+}
+
+func (s *ServiceImpl) RateLimitAlgorithm() string {
+	return s.environment.RateLimitAlgorithm
+}
+
+func (s *ServiceImpl) RateLimitLimit() int {
+	return s.environment.RateLimitLimit
+}
+
+func (s *ServiceImpl) RateLimitPeriod() time.Duration {
+	return time.Duration(s.environment.RateLimitPeriodMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) RateLimitRoutes() []string {
+	return splitList(s.environment.RateLimitRoutes)
+}
+
+func (s *ServiceImpl) RateLimitKey() string {
+	return s.environment.RateLimitKey
+}
+
+func (s *ServiceImpl) AuthEnabled() bool {
+	return s.environment.AuthEnabled
+}
+
+func (s *ServiceImpl) AuthJWKSFile() string {
+	return s.environment.AuthJWKSFile
+}
+
+func (s *ServiceImpl) AuthJWKSUrl() string {
+	return s.environment.AuthJWKSUrl
+}
+
+func (s *ServiceImpl) AuthJWKSCacheTTL() time.Duration {
+	return time.Duration(s.environment.AuthJWKSCacheTTLMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) AuthJWTIssuer() string {
+	return s.environment.AuthJWTIssuer
+}
+
+func (s *ServiceImpl) AuthJWTAudience() string {
+	return s.environment.AuthJWTAudience
+}
+
+func (s *ServiceImpl) AuthRolesClaim() string {
+	return s.environment.AuthRolesClaim
+}
+
+func (s *ServiceImpl) AuthTenantClaim() string {
+	return s.environment.AuthTenantClaim
+}
+
+func (s *ServiceImpl) AuthApiKeysEnabled() bool {
+	return s.environment.AuthApiKeysEnabled
+}
+
+func (s *ServiceImpl) LogLevel() string {
+	return s.environment.LogLevel
+}
+
+func (s *ServiceImpl) LogPackageLevels() []string {
+	return splitList(s.environment.LogPackageLevels)
+}
+
+func (s *ServiceImpl) LogStdoutLevel() string {
+	return s.environment.LogStdoutLevel
+}
+
+func (s *ServiceImpl) LogStdoutFormat() string {
+	return s.environment.LogStdoutFormat
+}
+
+func (s *ServiceImpl) LogFilePath() string {
+	return s.environment.LogFilePath
+}
+
+func (s *ServiceImpl) LogFileLevel() string {
+	return s.environment.LogFileLevel
+}
+
+func (s *ServiceImpl) LogFileFormat() string {
+	return s.environment.LogFileFormat
+}
+
+func (s *ServiceImpl) LogFileMaxSizeMB() int {
+	return s.environment.LogFileMaxSizeMB
+}
+
+func (s *ServiceImpl) LogFileMaxAgeDays() int {
+	return s.environment.LogFileMaxAgeDays
+}
+
+func (s *ServiceImpl) LogFileMaxBackups() int {
+	return s.environment.LogFileMaxBackups
+}
+
+func (s *ServiceImpl) LogFileCompress() bool {
+	return s.environment.LogFileCompress
+}
+
+func (s *ServiceImpl) LogFileRotateInterval() time.Duration {
+	return time.Duration(s.environment.LogFileRotateIntervalMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) LogSyslogAddress() string {
+	return s.environment.LogSyslogAddress
+}
+
+func (s *ServiceImpl) LogSyslogLevel() string {
+	return s.environment.LogSyslogLevel
+}
+
+func (s *ServiceImpl) LogSyslogFormat() string {
+	return s.environment.LogSyslogFormat
+}
+
+func (s *ServiceImpl) LogSamplingEnabled() bool {
+	return s.environment.LogSamplingEnabled
+}
+
+func (s *ServiceImpl) LogSamplingFirst() int {
+	return s.environment.LogSamplingFirst
+}
+
+func (s *ServiceImpl) LogSamplingThereafter() int {
+	return s.environment.LogSamplingThereafter
+}
+
+func (s *ServiceImpl) LogSamplingInterval() time.Duration {
+	return time.Duration(s.environment.LogSamplingIntervalMs) * time.Millisecond
+}
+
+func (s *ServiceImpl) LogRedactEnabled() bool {
+	return s.environment.LogRedactEnabled
+}
+
+func (s *ServiceImpl) LogRedactKeys() []string {
+	return splitList(s.environment.LogRedactKeys)
+}
+
+func (s *ServiceImpl) LogRedactPatterns() []string {
+	return splitList(s.environment.LogRedactPatterns)
+}
+
+// Redacted returns the effective configuration by variable name, the secrets are masked.
+func (s *ServiceImpl) Redacted() map[string]interface{} {
+	values := map[string]interface{}{}
+	v := reflect.ValueOf(s.environment).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := field.Tag.Get("cfg")
+		if name == "" {
+			continue
+		}
+		if field.Tag.Get("redact") == "true" && !v.Field(i).IsZero() {
+			values[name] = redactedValue
+			continue
+		}
+		values[name] = v.Field(i).Interface()
+	}
+	return values
+}
+
+// splitList splits a comma separated value ignoring blank items.
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -1,2 +1,52 @@
 package environment
 
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestServiceImpl_Redacted(t *testing.T) {
+	os.Setenv("CACHE_PASSWORD", "s3cret")
+	defer os.Unsetenv("CACHE_PASSWORD")
+	s := New()
+	if err := s.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	values := s.Redacted()
+	if values["CACHE_PASSWORD"] != redactedValue || values["DATABASE_DSN"] != redactedValue {
+		t.Errorf("secrets not redacted: %v %v", values["CACHE_PASSWORD"], values["DATABASE_DSN"])
+	}
+	if values["CACHE_SENTINEL_PASSWORD"] != "" {
+		t.Errorf("CACHE_SENTINEL_PASSWORD = %v, want empty secrets shown as empty", values["CACHE_SENTINEL_PASSWORD"])
+	}
+	if values["CACHE_MODE"] != "standalone" {
+		t.Errorf("CACHE_MODE = %v, want standalone", values["CACHE_MODE"])
+	}
+}
+
+func TestServiceImpl_HttpFrameOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "default", want: "DENY"},
+		{name: "same origin", value: "SAMEORIGIN", want: "SAMEORIGIN"},
+		{name: "off", value: "off", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("HTTP_FRAME_OPTIONS", tt.value)
+			defer os.Unsetenv("HTTP_FRAME_OPTIONS")
+			s := New()
+			if err := s.Init(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := s.HttpFrameOptions(); got != tt.want {
+				t.Errorf("HttpFrameOptions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+)
+
+func (s *ServiceImpl) handleCreateApiKey(c echo.Context) error {
+	kReq := new(models.ApiKeyRequest)
+	if err := c.Bind(kReq); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	key := models.ApiKey{
+		Name:      *kReq.Name,
+		Scopes:    strings.Join(kReq.Scopes, " "),
+		Tenant:    kReq.Tenant,
+		ExpiresAt: kReq.ExpiresAt,
+	}
+	if principal := ctxs.GetPrincipalFromContext(ctx); principal != nil {
+		key.CreatedBy = &principal.Subject
+	}
+	plain, err := s.Sis().Handlers().CreateApiKey(ctx, &key)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, &models.ApiKeyCreatedResponse{
+		ApiKeyResponse: apiKeyResponse(&key),
+		Key:            plain,
+	})
+}
+
+func (s *ServiceImpl) handleGetApiKeys(c echo.Context) error {
+	var keys []models.ApiKey
+	if err := s.Sis().Handlers().GetApiKeys(c.Request().Context(), &keys); err != nil {
+		return err
+	}
+
+	kResponses := make([]models.ApiKeyResponse, 0, len(keys))
+	for i := range keys {
+		kResponses = append(kResponses, apiKeyResponse(&keys[i]))
+	}
+	return c.JSON(http.StatusOK, &kResponses)
+}
+
+func (s *ServiceImpl) handleRevokeApiKey(c echo.Context) error {
+	apiKeyId := c.Param("apiKeyId")
+	if apiKeyId == "" {
+		return errors.ApiKeyIdRequired
+	}
+	key := models.ApiKey{
+		Id: apiKeyId,
+	}
+	if err := s.Sis().Handlers().RevokeApiKey(c.Request().Context(), &key); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, apiKeyResponse(&key))
+}
+
+func apiKeyResponse(key *models.ApiKey) models.ApiKeyResponse {
+	return models.ApiKeyResponse{
+		Id:         key.Id,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     strings.Fields(key.Scopes),
+		Tenant:     key.Tenant,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
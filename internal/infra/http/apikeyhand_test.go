@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// apiKeyHandlers keeps the api keys in memory.
+type apiKeyHandlers struct {
+	services.NoopHandlers
+	keys []models.ApiKey
+}
+
+func (h *apiKeyHandlers) WithSis(_ services.Sis) services.Handlers {
+	return h
+}
+
+func (h *apiKeyHandlers) CreateApiKey(_ context.Context, key *models.ApiKey) (string, error) {
+	key.Id = "key-1"
+	key.Prefix = "a1b2c3"
+	key.CreatedAt = time.Now()
+	h.keys = append(h.keys, *key)
+	return "bpl_a1b2c3_secret", nil
+}
+
+func (h *apiKeyHandlers) GetApiKeys(_ context.Context, keys *[]models.ApiKey) error {
+	*keys = append([]models.ApiKey{}, h.keys...)
+	return nil
+}
+
+func (h *apiKeyHandlers) RevokeApiKey(_ context.Context, key *models.ApiKey) error {
+	for i := range h.keys {
+		if h.keys[i].Id == key.Id {
+			now := time.Now()
+			h.keys[i].RevokedAt = &now
+			*key = h.keys[i]
+			return nil
+		}
+	}
+	return errors.ApiKeyNotFound
+}
+
+func TestServiceImpl_ApiKeys(t *testing.T) {
+	handlers := &apiKeyHandlers{}
+	serviceImpl := New().WithAuthenticator(AuthSchemeBearer, tokenAuthenticator{
+		"admin":  {Subject: "admin-1", Roles: []string{RoleAdmin}},
+		"reader": {Subject: "user-1", Roles: []string{RoleUsersRead}},
+	})
+	services.New().WithHandlers(handlers).WithHttpServer(serviceImpl)
+	if err := serviceImpl.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	serve := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		serviceImpl.echo.ServeHTTP(rec, req)
+		return rec
+	}
+
+	body := `{"name":"ci","scopes":["users:read","users:write"],"tenant":"acme"}`
+	if rec := serve(http.MethodPost, "/admin/api-keys", "reader", body); rec.Code != http.StatusForbidden {
+		t.Errorf("create without the admin role = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	rec := serve(http.MethodPost, "/admin/api-keys", "admin", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create = %d: %s", rec.Code, rec.Body.String())
+	}
+	created := new(models.ApiKeyCreatedResponse)
+	if err := json.Unmarshal(rec.Body.Bytes(), created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Key != "bpl_a1b2c3_secret" || created.Id != "key-1" || created.CreatedBy == nil || *created.CreatedBy != "admin-1" {
+		t.Errorf("created = %+v, want the key created by the principal", created)
+	}
+	if stored := handlers.keys[0]; stored.Scopes != "users:read users:write" || stored.Tenant == nil || *stored.Tenant != "acme" {
+		t.Errorf("stored = %+v, want the scopes space separated and the tenant", stored)
+	}
+
+	rec = serve(http.MethodGet, "/admin/api-keys", "admin", "")
+	listed := make([]models.ApiKeyResponse, 0)
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || len(listed) != 1 || strings.Contains(rec.Body.String(), "secret") {
+		t.Errorf("list = %d %s, want the key without its secret", rec.Code, rec.Body.String())
+	}
+
+	if rec := serve(http.MethodDelete, "/admin/api-keys/unknown", "admin", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("revoke unknown = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	rec = serve(http.MethodDelete, "/admin/api-keys/key-1", "admin", "")
+	revoked := new(models.ApiKeyResponse)
+	if err := json.Unmarshal(rec.Body.Bytes(), revoked); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || revoked.RevokedAt == nil {
+		t.Errorf("revoke = %d %s, want the key revoked", rec.Code, rec.Body.String())
+	}
+}
+
+func TestServiceImpl_ApiKeysWithoutAuth(t *testing.T) {
+	serviceImpl := New()
+	services.New().WithHandlers(&apiKeyHandlers{}).WithHttpServer(serviceImpl)
+	if err := serviceImpl.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, route := range serviceImpl.echo.Routes() {
+		if strings.HasPrefix(route.Path, "/admin") {
+			t.Errorf("route %v %v is served without auth", route.Method, route.Path)
+		}
+	}
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/admin/api-keys", strings.NewReader(`{"name":"ci","scopes":["admin"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	serviceImpl.echo.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("create without auth = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
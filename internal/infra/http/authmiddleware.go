@@ -0,0 +1,106 @@
+package http
+
+import (
+	goerrors "errors"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/auth"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/labstack/echo/v4"
+	"sort"
+	"strings"
+)
+
+const (
+	HeaderAuthorization   = "Authorization"
+	HeaderWWWAuthenticate = "WWW-Authenticate"
+	AuthSchemeBearer      = "Bearer"
+	AuthSchemeApiKey      = "ApiKey"
+
+	RoleUsersRead  = "users:read"
+	RoleUsersWrite = "users:write"
+	RoleAdmin      = "admin"
+)
+
+// AuthMiddleware puts the principal of the Authorization header, or of a client certificate, on the context.
+func AuthMiddleware(authenticators map[string]auth.Authenticator) func(h echo.HandlerFunc) echo.HandlerFunc {
+	challenge := make([]string, 0, len(authenticators))
+	for scheme := range authenticators {
+		challenge = append(challenge, scheme)
+	}
+	sort.Strings(challenge)
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(HeaderAuthorization)
+			if header == "" && ctxs.GetPrincipalFromContext(c.Request().Context()) != nil {
+				// authenticated by its client certificate
+				return h(c)
+			}
+			scheme, credentials := splitAuthorization(header)
+			authenticator := authenticatorOf(authenticators, scheme)
+			if authenticator == nil || credentials == "" {
+				if len(challenge) > 0 {
+					c.Response().Header().Set(HeaderWWWAuthenticate, strings.Join(challenge, ", "))
+				}
+				return errors.AuthenticationRequired
+			}
+
+			ctx := c.Request().Context()
+			principal, err := authenticator.Authenticate(ctx, credentials)
+			if err != nil {
+				if goerrors.Is(err, errors.AuthenticationInvalid) {
+					c.Response().Header().Set(HeaderWWWAuthenticate, scheme+` error="invalid_token"`)
+				}
+				return err
+			}
+
+			withPrincipal(c, principal)
+			return h(c)
+		}
+	}
+}
+
+// withPrincipal also binds the subject and tenant of principal to the request logger.
+func withPrincipal(c echo.Context, principal *structs.Principal) {
+	ctx := ctxs.ContextWithPrincipal(c.Request().Context(), principal)
+	if reqLog := ctxs.GetLoggerFromContext(ctx); reqLog != nil {
+		ctx = ctxs.ContextWithLogger(ctx, reqLog.With(map[string]interface{}{
+			"user_id": principal.Subject,
+			"tenant":  principal.Tenant,
+		}))
+	}
+	c.SetRequest(c.Request().WithContext(ctx))
+}
+
+// RequireRoles lets through the principals with any of roles.
+func RequireRoles(roles ...string) func(h echo.HandlerFunc) echo.HandlerFunc {
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal := ctxs.GetPrincipalFromContext(c.Request().Context())
+			if principal == nil {
+				return errors.AuthenticationRequired
+			}
+			if !principal.HasAnyRole(roles...) {
+				return errors.PermissionDenied.WithDetails(map[string]interface{}{"roles": roles})
+			}
+			return h(c)
+		}
+	}
+}
+
+func splitAuthorization(header string) (scheme string, credentials string) {
+	i := strings.IndexByte(header, ' ')
+	if i < 0 {
+		return header, ""
+	}
+	return header[:i], strings.TrimSpace(header[i+1:])
+}
+
+func authenticatorOf(authenticators map[string]auth.Authenticator, scheme string) auth.Authenticator {
+	for s, authenticator := range authenticators {
+		if strings.EqualFold(s, scheme) {
+			return authenticator
+		}
+	}
+	return nil
+}
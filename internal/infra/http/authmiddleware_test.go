@@ -0,0 +1,68 @@
+package http
+
+import (
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/auth"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type tokenAuthenticator map[string]*structs.Principal
+
+func (a tokenAuthenticator) Authenticate(_ context.Context, token string) (*structs.Principal, error) {
+	if principal, ok := a[token]; ok {
+		return principal, nil
+	}
+	return nil, errors.AuthenticationInvalid
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(services.NewNoopLogger())
+	authenticators := map[string]auth.Authenticator{AuthSchemeBearer: tokenAuthenticator{
+		"reader": {Subject: "user-1", Roles: []string{RoleUsersRead}, Tenant: "acme"},
+		"writer": {Subject: "user-2", Roles: []string{RoleUsersWrite}},
+	}}
+	e.DELETE("/users/:userId", func(c echo.Context) error {
+		return c.String(http.StatusOK, ctxs.GetPrincipalFromContext(c.Request().Context()).Subject)
+	}, LogMiddleware(services.NewNoopLogger()), AuthMiddleware(authenticators), RequireRoles(RoleUsersWrite))
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+		challenge     string
+	}{
+		{name: "missing", status: http.StatusUnauthorized, challenge: "Bearer"},
+		{name: "unknown scheme", authorization: "Basic dXNlcjpwYXNz", status: http.StatusUnauthorized, challenge: "Bearer"},
+		{name: "invalid token", authorization: "Bearer forged", status: http.StatusUnauthorized, challenge: `Bearer error="invalid_token"`},
+		{name: "without role", authorization: "Bearer reader", status: http.StatusForbidden},
+		{name: "with role", authorization: "bearer writer", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+			if tt.authorization != "" {
+				req.Header.Set(HeaderAuthorization, tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %v, want %v", rec.Code, tt.status)
+			}
+			if challenge := rec.Header().Get(HeaderWWWAuthenticate); challenge != tt.challenge {
+				t.Errorf("challenge = %q, want %q", challenge, tt.challenge)
+			}
+			if tt.status == http.StatusOK && rec.Body.String() != "user-2" {
+				t.Errorf("principal = %v, want user-2", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/labstack/echo/v4"
+	"io"
+)
+
+type (
+	limitedBody struct {
+		io.ReadCloser
+		remaining int64
+		exceeded  bool
+	}
+)
+
+// BodyLimitMiddleware refuses request bodies larger than limit with errors.RequestBodyTooLarge.
+func BodyLimitMiddleware(limit int64) func(h echo.HandlerFunc) echo.HandlerFunc {
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.ContentLength > limit {
+				return errors.RequestBodyTooLarge
+			}
+			req.Body = &limitedBody{ReadCloser: req.Body, remaining: limit}
+			return h(c)
+		}
+	}
+}
+
+// Read never returns the bytes past remaining, a decoder could otherwise accept a body ending there.
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, errors.RequestBodyTooLarge
+	}
+	// one byte more than remaining tells a body of exactly the limit from a larger one
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		b.exceeded = true
+		return int(b.remaining), errors.RequestBodyTooLarge
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
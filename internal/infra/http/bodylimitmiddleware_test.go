@@ -0,0 +1,50 @@
+package http
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(services.NewNoopLogger())
+	e.POST("/users", func(c echo.Context) error {
+		body := make(map[string]interface{})
+		if err := c.Bind(&body); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusCreated)
+	}, BodyLimitMiddleware(16))
+
+	tests := []struct {
+		name    string
+		body    string
+		chunked bool
+		status  int
+	}{
+		{name: "at the limit", body: `{"name":"12345"}`, status: http.StatusCreated},
+		{name: "over the limit", body: `{"name":"123456"}`, status: http.StatusRequestEntityTooLarge},
+		{name: "over the limit without length", body: `{"name":"123456"}`, chunked: true, status: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.chunked {
+				req.ContentLength = -1
+				req.Body = ioutil.NopCloser(strings.NewReader(tt.body))
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %v, want %v: %v", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
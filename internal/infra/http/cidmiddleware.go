@@ -0,0 +1,53 @@
+package http
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"regexp"
+)
+
+const HeaderCorrelationID = "X-Correlation-ID"
+
+// validCid refuses the ids that could break a header or a log line.
+var validCid = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]{0,127}$`)
+
+type (
+	// CidTransport propagates the cid of the request context to outbound requests.
+	CidTransport struct {
+		Base http.RoundTripper
+	}
+)
+
+// CidMiddleware puts the X-Correlation-ID of the request, or a new uuid, on the context and the response.
+func CidMiddleware() func(h echo.HandlerFunc) echo.HandlerFunc {
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			cid := req.Header.Get(HeaderCorrelationID)
+			if !validCid.MatchString(cid) {
+				cid = uuid.New().String()
+			}
+			req.Header.Set(HeaderCorrelationID, cid)
+			c.SetRequest(req.WithContext(ctxs.ContextWithCid(req.Context(), cid)))
+			c.Response().Header().Set(HeaderCorrelationID, cid)
+			return h(c)
+		}
+	}
+}
+
+func (t *CidTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	cid := ctxs.GetCidFromContext(req.Context())
+	if cid == nil || req.Header.Get(HeaderCorrelationID) != "" {
+		return base.RoundTrip(req)
+	}
+	// a RoundTripper must not modify the request it was given
+	out := req.Clone(req.Context())
+	out.Header.Set(HeaderCorrelationID, *cid)
+	return base.RoundTrip(out)
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCidMiddleware(t *testing.T) {
+	e := echo.New()
+	e.GET("/users", func(c echo.Context) error {
+		cid := ctxs.GetCidFromContext(c.Request().Context())
+		if cid == nil {
+			return c.String(http.StatusOK, "")
+		}
+		return c.String(http.StatusOK, *cid)
+	}, CidMiddleware())
+
+	tests := []struct {
+		name     string
+		cid      string
+		generate bool
+	}{
+		{name: "received", cid: "f5f3b3a2-6c1e-4d0b-9d5e-2f3f1c0a9b8e"},
+		{name: "missing", generate: true},
+		{name: "invalid", cid: "bad cid\r\n", generate: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if tt.cid != "" {
+				req.Header.Set(HeaderCorrelationID, tt.cid)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			cid := rec.Header().Get(HeaderCorrelationID)
+			if rec.Body.String() != cid {
+				t.Errorf("context cid = %v, response cid = %v", rec.Body.String(), cid)
+			}
+			if _, err := uuid.Parse(cid); tt.generate && err != nil {
+				t.Errorf("cid = %v, want a new uuid", cid)
+			}
+			if !tt.generate && cid != tt.cid {
+				t.Errorf("cid = %v, want %v", cid, tt.cid)
+			}
+		})
+	}
+}
+
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, req)
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestCidTransport_RoundTrip(t *testing.T) {
+	base := &recordingTransport{}
+	transport := &CidTransport{Base: base}
+	ctx := ctxs.ContextWithCid(context.Background(), "mycid")
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want string
+	}{
+		{name: "from context", req: httptest.NewRequest(http.MethodGet, "http://keys.example.com/jwks", nil).WithContext(ctx), want: "mycid"},
+		{name: "without cid", req: httptest.NewRequest(http.MethodGet, "http://keys.example.com/jwks", nil), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := transport.RoundTrip(tt.req); err != nil {
+				t.Fatal(err)
+			}
+			sent := base.requests[len(base.requests)-1]
+			if cid := sent.Header.Get(HeaderCorrelationID); cid != tt.want {
+				t.Errorf("cid = %q, want %q", cid, tt.want)
+			}
+			if tt.req.Header.Get(HeaderCorrelationID) != "" {
+				t.Error("the request given was modified")
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://keys.example.com/jwks", nil).WithContext(ctx)
+	req.Header.Set(HeaderCorrelationID, "explicit")
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if cid := base.requests[len(base.requests)-1].Header.Get(HeaderCorrelationID); cid != "explicit" {
+		t.Errorf("cid = %q, want the one of the request kept", cid)
+	}
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"crypto/x509"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/labstack/echo/v4"
+)
+
+// ClientCertMiddleware puts the principal of the verified client certificate on the request context.
+func ClientCertMiddleware() func(h echo.HandlerFunc) echo.HandlerFunc {
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			state := c.Request().TLS
+			if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+				return h(c)
+			}
+			withPrincipal(c, clientCertPrincipal(state.VerifiedChains[0][0]))
+			return h(c)
+		}
+	}
+}
+
+// clientCertPrincipal takes the roles from the organizational units and the tenant from the organization.
+func clientCertPrincipal(cert *x509.Certificate) *structs.Principal {
+	principal := &structs.Principal{
+		Subject: cert.Subject.CommonName,
+		Roles:   append([]string{}, cert.Subject.OrganizationalUnit...),
+	}
+	if len(cert.URIs) > 0 {
+		principal.Subject = cert.URIs[0].String()
+	}
+	if len(cert.Subject.Organization) > 0 {
+		principal.Tenant = cert.Subject.Organization[0]
+	}
+	return principal
+}
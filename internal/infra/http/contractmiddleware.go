@@ -0,0 +1,136 @@
+package http
+
+import (
+	"bytes"
+	goerrors "errors"
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/openapi"
+	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	"io/ioutil"
+	"strings"
+)
+
+// ContractMiddleware validates the requests of the routes in contract. With validateResponses the
+// responses not matching the contract are logged, they are still sent.
+func ContractMiddleware(contract *openapi3.Swagger, log services.Logger, validateResponses bool) func(h echo.HandlerFunc) echo.HandlerFunc {
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			route := contractRoute(contract, c)
+			if route == nil {
+				return h(c)
+			}
+			req := c.Request()
+			ctx := req.Context()
+			pathParams := make(map[string]string, len(c.ParamNames()))
+			for i, name := range c.ParamNames() {
+				pathParams[name] = c.ParamValues()[i]
+			}
+			input := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					MultiError: true,
+					// the security of the operations is checked by AuthMiddleware
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				},
+			}
+			if err := openapi3filter.ValidateRequest(ctx, input); err != nil {
+				return contractRequestError(err)
+			}
+			if !validateResponses {
+				return h(c)
+			}
+
+			recorder := &recorderWriter{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			if err := h(c); err != nil {
+				// commit the error response now so it is validated as well
+				c.Error(err)
+			}
+			c.Response().Writer = recorder.ResponseWriter
+
+			err := openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 c.Response().Status,
+				Header:                 c.Response().Header(),
+				Body:                   ioutil.NopCloser(bytes.NewReader(recorder.body.Bytes())),
+				Options:                input.Options,
+			})
+			if err != nil {
+				log.Warn(ctx, fmt.Sprintf("Response %v:%v does not match the contract", req.Method, c.Path()), map[string]interface{}{
+					"status": c.Response().Status,
+					"error":  err,
+				})
+			}
+			return nil
+		}
+	}
+}
+
+func contractRoute(contract *openapi3.Swagger, c echo.Context) *openapi3filter.Route {
+	path := openapi.Path(c.Path())
+	item := contract.Paths[path]
+	if item == nil {
+		return nil
+	}
+	operation := item.GetOperation(c.Request().Method)
+	if operation == nil {
+		return nil
+	}
+	return &openapi3filter.Route{
+		Swagger:   contract,
+		Path:      path,
+		PathItem:  item,
+		Method:    c.Request().Method,
+		Operation: operation,
+	}
+}
+
+// contractRequestError is a 422 when only the body does not match its schema and a 400 otherwise, a
+// body over the limit of BodyLimitMiddleware keeps its errors.RequestBodyTooLarge.
+func contractRequestError(err error) error {
+	errs, ok := err.(openapi3.MultiError)
+	if !ok {
+		errs = openapi3.MultiError{err}
+	}
+	fields := make([]models.FieldError, 0)
+	for _, e := range errs {
+		var bodyFields []models.FieldError
+		if reqErr, ok := e.(*openapi3filter.RequestError); ok && reqErr.RequestBody != nil {
+			if goerrors.Is(reqErr.Err, errors.RequestBodyTooLarge) {
+				return reqErr.Err
+			}
+			bodyFields = schemaFieldErrors(reqErr.Err)
+		}
+		if len(bodyFields) == 0 {
+			return errors.ContractRequestInvalid.WithDetails(map[string]interface{}{"reason": e.Error()}).Wrap(err)
+		}
+		fields = append(fields, bodyFields...)
+	}
+	return errors.ValidationFailed.WithDetails(map[string]interface{}{"fields": fields}).Wrap(err)
+}
+
+func schemaFieldErrors(err error) []models.FieldError {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		fields := make([]models.FieldError, 0, len(e))
+		for _, ee := range e {
+			fields = append(fields, schemaFieldErrors(ee)...)
+		}
+		return fields
+	case *openapi3.SchemaError:
+		return []models.FieldError{{
+			Field:   strings.Join(e.JSONPointer(), "."),
+			Rule:    e.SchemaField,
+			Message: e.Reason,
+		}}
+	default:
+		return nil
+	}
+}
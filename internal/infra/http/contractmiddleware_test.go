@@ -0,0 +1,79 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/dalmarcogd/bpl-go/internal/infra/openapi"
+	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestContractMiddleware(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(services.NewNoopLogger())
+	spec := openapi.New("test", "1")
+	spec.Document(e.POST("/users", func(c echo.Context) error {
+		return c.JSON(http.StatusCreated, &models.UserResponse{Id: "1"})
+	}), openapi.Operation{
+		Request:   models.UserRequest{},
+		Responses: map[int]interface{}{http.StatusCreated: models.UserResponse{}},
+	})
+	contract, err := spec.Build(e.Routes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Use(BodyLimitMiddleware(64))
+	e.Use(ContractMiddleware(contract, services.NewNoopLogger(), true))
+
+	tests := []struct {
+		name    string
+		body    string
+		chunked bool
+		status  int
+		fields  []string
+	}{
+		{name: "valid", body: `{"name":"john","email":"john@doe.com"}`, status: http.StatusCreated},
+		{name: "malformed", body: `{"name":`, status: http.StatusBadRequest},
+		{name: "schema", body: `{"name":"","email":"john"}`, status: http.StatusUnprocessableEntity, fields: []string{"email", "name"}},
+		{name: "too large", body: `{"name":"` + strings.Repeat("j", 64) + `"}`, chunked: true, status: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.fields == nil {
+				return
+			}
+			problem := struct {
+				Details struct {
+					Fields []models.FieldError `json:"fields"`
+				} `json:"details"`
+			}{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			fields := make([]string, 0)
+			for _, f := range problem.Details.Fields {
+				fields = append(fields, f.Field)
+			}
+			sort.Strings(fields)
+			if strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("fields = %v, want %v", problem.Details.Fields, tt.fields)
+			}
+		})
+	}
+}
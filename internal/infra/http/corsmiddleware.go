@@ -0,0 +1,78 @@
+package http
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const corsAllowAnyOrigin = "*"
+
+var (
+	corsAllowHeaders = []string{
+		HeaderAuthorization, echo.HeaderContentType, HeaderCorrelationID, HeaderIdempotencyKey, HeaderTraceParent,
+	}
+	corsExposeHeaders = []string{
+		HeaderCorrelationID, HeaderIdempotentReplayed, HeaderRateLimitLimit, HeaderRateLimitRemaining,
+		HeaderRateLimitReset, HeaderRetryAfter, HeaderWWWAuthenticate, echo.HeaderLocation,
+	}
+	corsAllowMethods = []string{
+		http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+	}
+)
+
+// validateCORS refuses "*" with credentials, which would let any site call the api as its users.
+func validateCORS(cors structs.CORS) error {
+	for _, origin := range cors.AllowOrigins {
+		if origin == corsAllowAnyOrigin && cors.AllowCredentials {
+			return errors.CORSAnyOriginWithCredentials
+		}
+	}
+	return nil
+}
+
+// CORSMiddleware lets the browsers on the origins of cors call the api.
+func CORSMiddleware(cors structs.CORS) func(h echo.HandlerFunc) echo.HandlerFunc {
+	allowed := make(map[string]bool, len(cors.AllowOrigins))
+	for _, origin := range cors.AllowOrigins {
+		allowed[strings.TrimSuffix(origin, "/")] = true
+	}
+	maxAge := strconv.Itoa(int(cors.MaxAge.Seconds()))
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			header := c.Response().Header()
+			header.Add(echo.HeaderVary, echo.HeaderOrigin)
+			origin := req.Header.Get(echo.HeaderOrigin)
+			preflight := req.Method == http.MethodOptions && req.Header.Get(echo.HeaderAccessControlRequestMethod) != ""
+			if origin == "" || !(allowed[corsAllowAnyOrigin] || allowed[origin]) {
+				if preflight {
+					return c.NoContent(http.StatusNoContent)
+				}
+				return h(c)
+			}
+
+			if allowed[origin] {
+				header.Set(echo.HeaderAccessControlAllowOrigin, origin)
+			} else {
+				header.Set(echo.HeaderAccessControlAllowOrigin, corsAllowAnyOrigin)
+			}
+			if cors.AllowCredentials {
+				header.Set(echo.HeaderAccessControlAllowCredentials, "true")
+			}
+			if !preflight {
+				header.Set(echo.HeaderAccessControlExposeHeaders, strings.Join(corsExposeHeaders, ", "))
+				return h(c)
+			}
+			header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestMethod)
+			header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestHeaders)
+			header.Set(echo.HeaderAccessControlAllowMethods, strings.Join(corsAllowMethods, ", "))
+			header.Set(echo.HeaderAccessControlAllowHeaders, strings.Join(corsAllowHeaders, ", "))
+			header.Set(echo.HeaderAccessControlMaxAge, maxAge)
+			return c.NoContent(http.StatusNoContent)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(CORSMiddleware(structs.CORS{AllowOrigins: []string{"https://app.example.com/"}, MaxAge: time.Minute}))
+	e.GET("/users", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	tests := []struct {
+		name      string
+		method    string
+		origin    string
+		preflight bool
+		status    int
+		allowed   bool
+	}{
+		{name: "allowed", method: http.MethodGet, origin: "https://app.example.com", status: http.StatusOK, allowed: true},
+		{name: "allowed preflight", method: http.MethodOptions, origin: "https://app.example.com", preflight: true, status: http.StatusNoContent, allowed: true},
+		{name: "other origin", method: http.MethodGet, origin: "https://evil.example.com", status: http.StatusOK},
+		{name: "other origin preflight", method: http.MethodOptions, origin: "https://evil.example.com", preflight: true, status: http.StatusNoContent},
+		{name: "same origin", method: http.MethodGet, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/users", nil)
+			if tt.origin != "" {
+				req.Header.Set(echo.HeaderOrigin, tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodGet)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %v, want %v", rec.Code, tt.status)
+			}
+			origin := rec.Header().Get(echo.HeaderAccessControlAllowOrigin)
+			if tt.allowed && origin != tt.origin || !tt.allowed && origin != "" {
+				t.Errorf("allowed origin = %q", origin)
+			}
+			if maxAge := rec.Header().Get(echo.HeaderAccessControlMaxAge); tt.allowed && tt.preflight && maxAge != "60" {
+				t.Errorf("max age = %q, want 60", maxAge)
+			}
+		})
+	}
+}
+
+func TestCORSMiddleware_AnyOrigin(t *testing.T) {
+	e := echo.New()
+	e.Use(CORSMiddleware(structs.CORS{AllowOrigins: []string{"https://app.example.com", corsAllowAnyOrigin}}))
+	e.GET("/users", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for origin, want := range map[string]string{
+		"https://app.example.com":   "https://app.example.com",
+		"https://other.example.com": corsAllowAnyOrigin,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set(echo.HeaderOrigin, origin)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if got := rec.Header().Get(echo.HeaderAccessControlAllowOrigin); got != want {
+			t.Errorf("allowed origin of %v = %q, want %q", origin, got, want)
+		}
+		if got := rec.Header().Get(echo.HeaderAccessControlAllowCredentials); got != "" {
+			t.Errorf("allow credentials of %v = %q, want none", origin, got)
+		}
+	}
+}
+
+func TestValidateCORS(t *testing.T) {
+	tests := []struct {
+		name    string
+		cors    structs.CORS
+		wantErr error
+	}{
+		{name: "origins with credentials", cors: structs.CORS{AllowOrigins: []string{"https://app.example.com"}, AllowCredentials: true}},
+		{name: "any origin", cors: structs.CORS{AllowOrigins: []string{corsAllowAnyOrigin}}},
+		{name: "any origin with credentials", cors: structs.CORS{AllowOrigins: []string{"https://app.example.com", corsAllowAnyOrigin}, AllowCredentials: true}, wantErr: errors.CORSAnyOriginWithCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateCORS(tt.cors); err != tt.wantErr {
+				t.Errorf("validateCORS() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
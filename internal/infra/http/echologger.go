@@ -0,0 +1,169 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"io"
+	"io/ioutil"
+	stdlog "log"
+	"strings"
+)
+
+// stdLoggerFrames are the frames a standard logger adds above its writer.
+const stdLoggerFrames = 3
+
+type (
+	echoLogger struct {
+		log    services.Logger
+		prefix string
+	}
+	// echoErrorWriter adapts services.Logger to the standard logger of net/http.
+	echoErrorWriter struct {
+		log services.Logger
+	}
+)
+
+// NewEcho builds an echo logging through log and writing errors as problems.
+func NewEcho(log services.Logger) *echo.Echo {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.Logger = newEchoLogger(log)
+	e.StdLogger = stdlog.New(&echoErrorWriter{log: log.WithCallerSkip(stdLoggerFrames)}, "", 0)
+	e.HTTPErrorHandler = ErrorHandler(log)
+	e.IPExtractor = echo.ExtractIPDirect()
+	return e
+}
+
+func newEchoLogger(l services.Logger) *echoLogger {
+	return &echoLogger{log: l.With(map[string]interface{}{"component": "echo"}).WithCallerSkip(1)}
+}
+
+func (l *echoLogger) Output() io.Writer {
+	return ioutil.Discard
+}
+
+func (l *echoLogger) SetOutput(_ io.Writer) {}
+
+func (l *echoLogger) Prefix() string {
+	return l.prefix
+}
+
+func (l *echoLogger) SetPrefix(p string) {
+	l.prefix = p
+}
+
+func (l *echoLogger) Level() log.Lvl {
+	return log.DEBUG
+}
+
+func (l *echoLogger) SetLevel(_ log.Lvl) {}
+
+func (l *echoLogger) SetHeader(_ string) {}
+
+func (l *echoLogger) Print(i ...interface{}) {
+	l.log.Info(context.Background(), fmt.Sprint(i...))
+}
+
+func (l *echoLogger) Printf(format string, args ...interface{}) {
+	l.log.Info(context.Background(), fmt.Sprintf(format, args...))
+}
+
+func (l *echoLogger) Printj(j log.JSON) {
+	l.log.Info(context.Background(), jsonMessage(j), j)
+}
+
+func (l *echoLogger) Debug(i ...interface{}) {
+	l.log.Debug(context.Background(), fmt.Sprint(i...))
+}
+
+func (l *echoLogger) Debugf(format string, args ...interface{}) {
+	l.log.Debug(context.Background(), fmt.Sprintf(format, args...))
+}
+
+func (l *echoLogger) Debugj(j log.JSON) {
+	l.log.Debug(context.Background(), jsonMessage(j), j)
+}
+
+func (l *echoLogger) Info(i ...interface{}) {
+	l.log.Info(context.Background(), fmt.Sprint(i...))
+}
+
+func (l *echoLogger) Infof(format string, args ...interface{}) {
+	l.log.Info(context.Background(), fmt.Sprintf(format, args...))
+}
+
+func (l *echoLogger) Infoj(j log.JSON) {
+	l.log.Info(context.Background(), jsonMessage(j), j)
+}
+
+func (l *echoLogger) Warn(i ...interface{}) {
+	l.log.Warn(context.Background(), fmt.Sprint(i...))
+}
+
+func (l *echoLogger) Warnf(format string, args ...interface{}) {
+	l.log.Warn(context.Background(), fmt.Sprintf(format, args...))
+}
+
+func (l *echoLogger) Warnj(j log.JSON) {
+	l.log.Warn(context.Background(), jsonMessage(j), j)
+}
+
+func (l *echoLogger) Error(i ...interface{}) {
+	l.log.Error(context.Background(), fmt.Sprint(i...))
+}
+
+func (l *echoLogger) Errorf(format string, args ...interface{}) {
+	l.log.Error(context.Background(), fmt.Sprintf(format, args...))
+}
+
+func (l *echoLogger) Errorj(j log.JSON) {
+	l.log.Error(context.Background(), jsonMessage(j), j)
+}
+
+func (l *echoLogger) Fatal(i ...interface{}) {
+	l.log.Fatal(context.Background(), fmt.Sprint(i...))
+}
+
+func (l *echoLogger) Fatalj(j log.JSON) {
+	l.log.Fatal(context.Background(), jsonMessage(j), j)
+}
+
+func (l *echoLogger) Fatalf(format string, args ...interface{}) {
+	l.log.Fatal(context.Background(), fmt.Sprintf(format, args...))
+}
+
+func (l *echoLogger) Panic(i ...interface{}) {
+	message := fmt.Sprint(i...)
+	l.log.Error(context.Background(), message)
+	panic(message)
+}
+
+func (l *echoLogger) Panicj(j log.JSON) {
+	message := jsonMessage(j)
+	l.log.Error(context.Background(), message, j)
+	panic(message)
+}
+
+func (l *echoLogger) Panicf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	l.log.Error(context.Background(), message)
+	panic(message)
+}
+
+func (w *echoErrorWriter) Write(p []byte) (int, error) {
+	w.log.Error(context.Background(), strings.TrimSpace(string(p)))
+	return len(p), nil
+}
+
+func jsonMessage(j log.JSON) string {
+	b, err := json.Marshal(j)
+	if err != nil {
+		return fmt.Sprint(j)
+	}
+	return string(b)
+}
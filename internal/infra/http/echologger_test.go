@@ -0,0 +1,83 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/dalmarcogd/bpl-go/internal/infra/logger"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/gommon/log"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"testing"
+)
+
+// newBufferLogger is a logger writing json entries of every level to the returned buffer.
+func newBufferLogger(t *testing.T) (*logger.ServiceImpl, *bytes.Buffer) {
+	formatter, _ := logger.NewFormatter(logger.FormatJSON)
+	out := new(bytes.Buffer)
+	serviceImpl := logger.New().WithSink(logger.NewSink("buffer", out, logrus.TraceLevel, formatter))
+	sm := services.New().WithLogger(serviceImpl)
+	if err := sm.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err := serviceImpl.SetLevel("trace"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = sm.Close()
+	})
+	out.Reset()
+	return serviceImpl, out
+}
+
+func lastEntry(t *testing.T, out *bytes.Buffer) map[string]interface{} {
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	entry := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &entry); err != nil {
+		t.Fatalf("entry %q: %v", lines[len(lines)-1], err)
+	}
+	return entry
+}
+
+func TestEchoLogger(t *testing.T) {
+	serviceImpl, out := newBufferLogger(t)
+	l := newEchoLogger(serviceImpl)
+
+	tests := []struct {
+		name  string
+		log   func()
+		level string
+		text  string
+	}{
+		{name: "debug", log: func() { l.Debugf("cache %v", "miss") }, level: "debug", text: "cache miss"},
+		{name: "print", log: func() { l.Print("started") }, level: "info", text: "started"},
+		{name: "warn", log: func() { l.Warn("slow") }, level: "warning", text: "slow"},
+		{name: "error json", log: func() { l.Errorj(log.JSON{"code": 1}) }, level: "error", text: `{"code":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.log()
+			entry := lastEntry(t, out)
+			if entry["level"] != tt.level || entry["text"] != tt.text || entry["component"] != "echo" {
+				t.Errorf("entry = %v, want %v %q of echo", entry, tt.level, tt.text)
+			}
+			if caller, _ := entry["caller"].(string); !strings.Contains(caller, "http.TestEchoLogger") {
+				t.Errorf("caller = %v, want the caller of the adapter", caller)
+			}
+		})
+	}
+}
+
+func TestNewEcho_StdLogger(t *testing.T) {
+	serviceImpl, out := newBufferLogger(t)
+	e := NewEcho(serviceImpl)
+
+	e.StdLogger.Printf("http: TLS handshake error from %v", "10.0.0.1:4242")
+	entry := lastEntry(t, out)
+	if entry["level"] != "error" || entry["text"] != "http: TLS handshake error from 10.0.0.1:4242" {
+		t.Errorf("entry = %v", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.Contains(caller, "http.TestNewEcho_StdLogger") {
+		t.Errorf("caller = %v, want the caller of the standard logger", caller)
+	}
+}
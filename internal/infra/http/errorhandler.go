@@ -0,0 +1,87 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+const (
+	MIMEApplicationProblemJSON = "application/problem+json"
+	problemTypeBlank           = "about:blank"
+)
+
+// ErrorHandler writes every error as an RFC 7807 problem.
+func ErrorHandler(log services.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+		ctx := c.Request().Context()
+		problem := newProblem(err)
+		problem.Instance = c.Request().URL.Path
+		if cid := ctxs.GetCidFromContext(ctx); cid != nil {
+			problem.Cid = *cid
+		}
+		if problem.Status >= http.StatusInternalServerError {
+			if reqLog := ctxs.GetLoggerFromContext(ctx); reqLog != nil {
+				log = reqLog
+			}
+			log.Error(ctx, fmt.Sprintf("Request %v:%v failed", c.Request().Method, c.Path()), map[string]interface{}{
+				"error": err,
+			})
+		}
+
+		var errW error
+		if c.Request().Method == http.MethodHead {
+			errW = c.NoContent(problem.Status)
+		} else {
+			c.Response().Header().Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+			c.Response().WriteHeader(problem.Status)
+			errW = json.NewEncoder(c.Response()).Encode(problem)
+		}
+		if errW != nil {
+			log.Error(ctx, fmt.Sprintf("Problem not written: %v", errW))
+		}
+	}
+}
+
+func newProblem(err error) *models.Problem {
+	if e, ok := errors.As(err); ok && e.Status < http.StatusInternalServerError {
+		return &models.Problem{
+			Type:    problemTypeBlank,
+			Title:   http.StatusText(e.Status),
+			Status:  e.Status,
+			Detail:  e.Message,
+			Code:    e.Code,
+			Details: e.Details,
+		}
+	}
+	if he, ok := err.(*echo.HTTPError); ok && he.Code < http.StatusInternalServerError {
+		if herr, ok := he.Internal.(*echo.HTTPError); ok {
+			he = herr
+		}
+		problem := &models.Problem{
+			Type:   problemTypeBlank,
+			Title:  http.StatusText(he.Code),
+			Status: he.Code,
+		}
+		if message, ok := he.Message.(string); ok {
+			problem.Detail = message
+		}
+		return problem
+	}
+	// the cause of internal errors can leak how the service works, it is logged instead
+	return &models.Problem{
+		Type:   problemTypeBlank,
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+		Detail: errors.InternalError.Message,
+		Code:   errors.InternalError.Code,
+	}
+}
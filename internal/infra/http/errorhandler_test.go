@@ -0,0 +1,53 @@
+package http
+
+import (
+	"encoding/json"
+	goerrors "errors"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{name: "not-found", err: errors.UserNotFound, status: http.StatusNotFound, code: "user_not_found", detail: "user not found"},
+		{name: "wrapped-conflict", err: errors.UserAlreadyExists.Wrap(goerrors.New("duplicate key")), status: http.StatusConflict, code: "user_already_exists", detail: "user already exists"},
+		{name: "echo", err: echo.ErrMethodNotAllowed, status: http.StatusMethodNotAllowed, detail: "Method Not Allowed"},
+		{name: "internal-hidden", err: goerrors.New("dial tcp 10.0.0.1:5432: connection refused"), status: http.StatusInternalServerError, code: "internal", detail: "internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = ErrorHandler(services.NewNoopLogger())
+			e.GET("/users/:userId", func(c echo.Context) error {
+				return tt.err
+			})
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+			if ct := rec.Header().Get(echo.HeaderContentType); ct != MIMEApplicationProblemJSON {
+				t.Errorf("content type = %v, want %v", ct, MIMEApplicationProblemJSON)
+			}
+			problem := new(models.Problem)
+			if err := json.Unmarshal(rec.Body.Bytes(), problem); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tt.status || problem.Status != tt.status || problem.Code != tt.code || problem.Detail != tt.detail {
+				t.Errorf("problem = %d %+v, want %d %v %v", rec.Code, problem, tt.status, tt.code, tt.detail)
+			}
+			if problem.Instance != "/users/1" {
+				t.Errorf("instance = %v, want /users/1", problem.Instance)
+			}
+		})
+	}
+}
@@ -2,17 +2,42 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
+	goerrors "errors"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/auth"
+	"github.com/dalmarcogd/bpl-go/internal/infra/openapi"
+	"github.com/dalmarcogd/bpl-go/internal/models"
 	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/labstack/echo/v4"
-	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	idempotencyTTL        = 24 * time.Hour
+	productionEnvironment = "production"
+	securitySchemeBearer  = "bearerAuth"
+	securitySchemeApiKey  = "apiKeyAuth"
 )
 
 type (
 	ServiceImpl struct {
+		services.NoopHealth
 		serviceManager services.Sis
 		ctx            context.Context
 		echo           *echo.Echo
+		server         *http.Server
+		redirect       *http.Server
+		tlsConfig      *tls.Config
+		spec           *openapi.Spec
 		address        string
+		authenticators map[string]auth.Authenticator
+		rateLimit      echo.MiddlewareFunc
+		contract       echo.MiddlewareFunc
 	}
 )
 
@@ -25,20 +50,115 @@ func (s *ServiceImpl) WithAddress(address string) *ServiceImpl {
 	return s
 }
 
+// WithAuthenticator authenticates the Authorization scheme with authenticator.
+func (s *ServiceImpl) WithAuthenticator(scheme string, authenticator auth.Authenticator) *ServiceImpl {
+	if s.authenticators == nil {
+		s.authenticators = map[string]auth.Authenticator{}
+	}
+	s.authenticators[scheme] = authenticator
+	return s
+}
+
 func (s *ServiceImpl) Init(ctx context.Context) error {
 	s.ctx = ctx
-	s.echo = echo.New()
-	s.echo.Logger.SetOutput(ioutil.Discard)
+	env := s.Sis().Environment()
+	if s.address == "" {
+		s.address = env.HttpAddress()
+	}
+	tlsConfig, err := newTLSConfig(ctx, s.Sis().Logger(), env)
+	if err != nil {
+		return err
+	}
+	s.tlsConfig = tlsConfig
+	s.echo = NewEcho(s.Sis().Logger())
+	if s.echo.IPExtractor, err = ipExtractor(env.HttpTrustedProxies()); err != nil {
+		return err
+	}
+	s.echo.Validator = &echoValidator{validator: s.Sis().Validator()}
+	s.echo.Binder = &validatingBinder{validator: s.Sis().Validator()}
+	s.spec = openapi.New(s.Sis().Environment().Service(), s.Sis().Environment().Version())
+	if err := s.initAuth(); err != nil {
+		return err
+	}
+	if err := s.initRateLimit(); err != nil {
+		return err
+	}
+	s.echo.Use(CidMiddleware())
+	s.echo.Use(SecureHeadersMiddleware(structs.SecureHeaders{
+		HSTSMaxAge:            env.HttpHSTSMaxAge(),
+		HSTSIncludeSubdomains: env.HttpHSTSIncludeSubdomains(),
+		FrameOptions:          env.HttpFrameOptions(),
+		ContentTypeNosniff:    env.HttpContentTypeNosniff(),
+	}))
+	s.echo.Use(MetricsMiddleware(s.Sis().Metrics()))
+	s.echo.Use(SpanMiddleware(s.Sis().Spans().Tracer()))
 	s.echo.Use(LogMiddleware(s.Sis().Logger()))
+	// inside the log, span and metrics middlewares so they see the 500 of a panic
+	s.echo.Use(RecoverMiddleware(s.Sis().Logger()))
+	if origins := env.HttpCORSAllowOrigins(); len(origins) > 0 {
+		cors := structs.CORS{
+			AllowOrigins:     origins,
+			AllowCredentials: env.HttpCORSAllowCredentials(),
+			MaxAge:           env.HttpCORSMaxAge(),
+		}
+		if err := validateCORS(cors); err != nil {
+			return err
+		}
+		s.echo.Use(CORSMiddleware(cors))
+	}
+	if limit := env.HttpMaxBodyBytes(); limit > 0 {
+		s.echo.Use(BodyLimitMiddleware(limit))
+	}
+	if s.mutualTLS() {
+		s.echo.Use(ClientCertMiddleware())
+	}
 	s.RegisterRoutes()
+	// built after the routes, which the contract is built from
+	if env.OpenAPIValidationEnabled() {
+		contract, err := s.spec.Build(s.echo.Routes())
+		if err != nil {
+			return err
+		}
+		s.contract = ContractMiddleware(contract, s.Sis().Logger(), env.Environment() != productionEnvironment)
+	}
+
+	s.server = &http.Server{
+		Addr:              s.address,
+		Handler:           s.echo,
+		TLSConfig:         s.tlsConfig,
+		ReadHeaderTimeout: env.HttpReadHeaderTimeout(),
+		ReadTimeout:       env.HttpReadTimeout(),
+		WriteTimeout:      env.HttpWriteTimeout(),
+		IdleTimeout:       env.HttpIdleTimeout(),
+		MaxHeaderBytes:    env.HttpMaxHeaderBytes(),
+		ErrorLog:          s.echo.StdLogger,
+	}
+	if !env.HttpHTTP2() {
+		// a non nil map keeps net/http from configuring http/2
+		s.server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	}
+	if s.tlsConfig != nil && env.HttpRedirectAddress() != "" {
+		s.redirect = &http.Server{
+			Addr:              env.HttpRedirectAddress(),
+			Handler:           redirectHandler(s.address),
+			ReadHeaderTimeout: env.HttpReadHeaderTimeout(),
+			ReadTimeout:       env.HttpReadTimeout(),
+			WriteTimeout:      env.HttpWriteTimeout(),
+			IdleTimeout:       env.HttpIdleTimeout(),
+			MaxHeaderBytes:    env.HttpMaxHeaderBytes(),
+			ErrorLog:          s.echo.StdLogger,
+		}
+	}
 	return nil
 }
 
 func (s *ServiceImpl) Close() error {
-	if err := s.echo.Shutdown(s.ctx); err != nil {
-		return err
+	if s.redirect != nil {
+		if err := s.redirect.Shutdown(s.ctx); err != nil {
+			return err
+		}
 	}
-	return s.echo.Close()
+	return s.server.Shutdown(s.ctx)
 }
 
 func (s *ServiceImpl) WithSis(c services.Sis) services.HttpServer {
@@ -51,15 +171,212 @@ func (s *ServiceImpl) Sis() services.Sis {
 }
 
 func (s *ServiceImpl) RegisterRoutes() *ServiceImpl {
-	group := s.echo.Group("/v1")
-	group.POST("/users", s.handleCreateUser)
-	group.PATCH("/users/:userId", s.handleUpdateUser)
-	group.GET("/users/:userId", s.handleGetUserById)
-	group.GET("/users", s.handleGetUsers)
-	group.DELETE("/users/:userId", s.handleDeleteUser)
+	middlewares := make([]echo.MiddlewareFunc, 0)
+	if s.authEnabled() {
+		middlewares = append(middlewares, AuthMiddleware(s.authenticators))
+	}
+	if s.rateLimit != nil {
+		middlewares = append(middlewares, s.rateLimit)
+	}
+	// replayed only to the principals allowed on the route, after requireRoles and validateContract
+	idempotency := IdempotencyMiddleware(s.Sis().Cache(), idempotencyTTL)
+
+	group := s.echo.Group("/v1", middlewares...)
+	s.spec.Document(group.POST("/users", s.handleCreateUser, s.requireRoles(RoleUsersWrite), s.validateContract, idempotency), openapi.Operation{
+		Summary:   "Create a user",
+		Tags:      []string{"users"},
+		Roles:     []string{RoleUsersWrite},
+		Request:   models.UserRequest{},
+		Responses: map[int]interface{}{http.StatusCreated: models.UserResponse{}},
+	})
+	s.spec.Document(group.PATCH("/users/:userId", s.handleUpdateUser, s.requireRoles(RoleUsersWrite), s.validateContract, idempotency), openapi.Operation{
+		Summary:   "Update a user",
+		Tags:      []string{"users"},
+		Roles:     []string{RoleUsersWrite},
+		Request:   models.UserRequest{},
+		Responses: map[int]interface{}{http.StatusOK: models.UserResponse{}},
+	})
+	s.spec.Document(group.GET("/users/:userId", s.handleGetUserById, s.requireRoles(RoleUsersRead, RoleUsersWrite), s.validateContract), openapi.Operation{
+		Summary:   "Get a user",
+		Tags:      []string{"users"},
+		Roles:     []string{RoleUsersRead, RoleUsersWrite},
+		Responses: map[int]interface{}{http.StatusOK: models.UserResponse{}},
+	})
+	s.spec.Document(group.GET("/users", s.handleGetUsers, s.requireRoles(RoleUsersRead, RoleUsersWrite), s.validateContract), openapi.Operation{
+		Summary:   "List the users",
+		Tags:      []string{"users"},
+		Roles:     []string{RoleUsersRead, RoleUsersWrite},
+		Responses: map[int]interface{}{http.StatusOK: []models.UserResponse{}},
+	})
+	s.spec.Document(group.DELETE("/users/:userId", s.handleDeleteUser, s.requireRoles(RoleUsersWrite), s.validateContract, idempotency), openapi.Operation{
+		Summary:   "Delete a user",
+		Tags:      []string{"users"},
+		Roles:     []string{RoleUsersWrite},
+		Responses: map[int]interface{}{http.StatusOK: models.UserResponse{}},
+	})
+
+	// without auth anyone could create keys
+	if s.authEnabled() {
+		s.registerAdminRoutes()
+	}
+
+	s.spec.Hide(s.echo.GET("/openapi.json", s.handleGetOpenAPI))
+	s.spec.Hide(s.echo.GET("/docs", openapi.UIHandler(s.Sis().Environment().Service(), "/openapi.json", "/docs/assets")))
+	s.spec.Hide(s.echo.GET("/docs/assets/*", openapi.AssetsHandler("/docs/assets/")))
 	return s
 }
 
+func (s *ServiceImpl) initAuth() error {
+	env := s.Sis().Environment()
+	if len(s.authenticators) == 0 && env.AuthEnabled() {
+		var keys auth.KeySet
+		switch {
+		case env.AuthJWKSFile() != "":
+			fileKeys, err := auth.NewFileKeySet(env.AuthJWKSFile())
+			if err != nil {
+				return err
+			}
+			keys = fileKeys
+		case env.AuthJWKSUrl() != "":
+			keys = auth.NewHTTPKeySet(env.AuthJWKSUrl(), env.AuthJWKSCacheTTL()).WithTransport(&CidTransport{})
+		}
+		if keys != nil {
+			s.WithAuthenticator(AuthSchemeBearer, auth.NewJWTAuthenticator(keys).
+				WithIssuer(env.AuthJWTIssuer()).
+				WithAudience(env.AuthJWTAudience()).
+				WithRolesClaim(env.AuthRolesClaim()).
+				WithTenantClaim(env.AuthTenantClaim()))
+		}
+		if env.AuthApiKeysEnabled() {
+			s.WithAuthenticator(AuthSchemeApiKey, auth.NewApiKeyAuthenticator(s.Sis().Handlers()))
+		}
+		if len(s.authenticators) == 0 {
+			return errors.AuthKeySourceRequired
+		}
+	}
+	if _, ok := s.authenticators[AuthSchemeBearer]; ok {
+		s.spec.WithSecurityScheme(securitySchemeBearer, openapi3.NewJWTSecurityScheme())
+	}
+	if _, ok := s.authenticators[AuthSchemeApiKey]; ok {
+		s.spec.WithSecurityScheme(securitySchemeApiKey, openapi3.NewSecurityScheme().
+			WithType("apiKey").
+			WithIn("header").
+			WithName(HeaderAuthorization).
+			WithDescription("Authorization: ApiKey <key>"))
+	}
+	return nil
+}
+
+func (s *ServiceImpl) initRateLimit() error {
+	env := s.Sis().Environment()
+	if !env.RateLimitEnabled() {
+		return nil
+	}
+	limit := structs.RateLimit{
+		Algorithm: env.RateLimitAlgorithm(),
+		Limit:     env.RateLimitLimit(),
+		Period:    env.RateLimitPeriod(),
+	}
+	if err := limit.Validate(); err != nil {
+		return err
+	}
+	routes, err := ParseRouteRateLimits(env.RateLimitRoutes(), limit)
+	if err != nil {
+		return err
+	}
+	s.rateLimit = RateLimitMiddleware(s.Sis().Cache(), s.Sis().Logger(), limit, routes, RateLimitKeyFuncOf(env.RateLimitKey()))
+	return nil
+}
+
+func (s *ServiceImpl) registerAdminRoutes() {
+	admin := s.echo.Group("/admin", AuthMiddleware(s.authenticators))
+	s.spec.Document(admin.POST("/api-keys", s.handleCreateApiKey, s.requireRoles(RoleAdmin), s.validateContract), openapi.Operation{
+		Summary:     "Create an api key",
+		Description: "The key is only returned by this response, it is stored hashed. Its scopes are the roles of the requests authenticated with it.",
+		Tags:        []string{"admin"},
+		Roles:       []string{RoleAdmin},
+		Request:     models.ApiKeyRequest{},
+		Responses:   map[int]interface{}{http.StatusCreated: models.ApiKeyCreatedResponse{}},
+	})
+	s.spec.Document(admin.GET("/api-keys", s.handleGetApiKeys, s.requireRoles(RoleAdmin), s.validateContract), openapi.Operation{
+		Summary:   "List the api keys",
+		Tags:      []string{"admin"},
+		Roles:     []string{RoleAdmin},
+		Responses: map[int]interface{}{http.StatusOK: []models.ApiKeyResponse{}},
+	})
+	s.spec.Document(admin.DELETE("/api-keys/:apiKeyId", s.handleRevokeApiKey, s.requireRoles(RoleAdmin), s.validateContract), openapi.Operation{
+		Summary:   "Revoke an api key",
+		Tags:      []string{"admin"},
+		Roles:     []string{RoleAdmin},
+		Responses: map[int]interface{}{http.StatusOK: models.ApiKeyResponse{}},
+	})
+}
+
+// validateContract runs after requireRoles so only the principals allowed on a route learn its contract.
+func (s *ServiceImpl) validateContract(h echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if s.contract == nil {
+			return h(c)
+		}
+		return s.contract(h)(c)
+	}
+}
+
+// requireRoles lets every request through when auth is disabled.
+func (s *ServiceImpl) requireRoles(roles ...string) echo.MiddlewareFunc {
+	if !s.authEnabled() {
+		return func(h echo.HandlerFunc) echo.HandlerFunc {
+			return h
+		}
+	}
+	return RequireRoles(roles...)
+}
+
+// authEnabled is whether requests are authenticated, by an Authenticator or by client certificates.
+func (s *ServiceImpl) authEnabled() bool {
+	return len(s.authenticators) > 0 || s.mutualTLS()
+}
+
+func (s *ServiceImpl) mutualTLS() bool {
+	return s.tlsConfig != nil && s.tlsConfig.ClientCAs != nil
+}
+
+// Routes returns the route table, without the fallbacks echo adds to the groups.
+func (s *ServiceImpl) Routes() []models.Route {
+	routes := make([]models.Route, 0)
+	for _, route := range s.echo.Routes() {
+		if openapi.IsGroupFallback(route) {
+			continue
+		}
+		routes = append(routes, models.Route{Method: route.Method, Path: route.Path, Name: route.Name})
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Run serves until Close, over tls when a certificate is configured.
 func (s *ServiceImpl) Run() error {
-	return s.echo.Start(s.address)
+	if s.redirect != nil {
+		go func() {
+			if err := s.redirect.ListenAndServe(); err != nil && !goerrors.Is(err, http.ErrServerClosed) {
+				s.Sis().Logger().Error(s.ctx, "Http redirect server failed", map[string]interface{}{"error": err})
+			}
+		}()
+	}
+	var err error
+	if s.tlsConfig != nil {
+		// the certificate is served by the GetCertificate of tlsConfig
+		err = s.server.ListenAndServeTLS("", "")
+	} else {
+		err = s.server.ListenAndServe()
+	}
+	if err != nil && !goerrors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
@@ -1 +1,135 @@
 package http
+
+import (
+	"context"
+	goerrors "errors"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServiceImpl_RoutesDocumented(t *testing.T) {
+	serviceImpl := New().
+		WithAuthenticator(AuthSchemeBearer, tokenAuthenticator{}).
+		WithAuthenticator(AuthSchemeApiKey, tokenAuthenticator{})
+	services.New().WithHttpServer(serviceImpl)
+	if err := serviceImpl.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, route := range serviceImpl.spec.Undocumented(serviceImpl.echo.Routes()) {
+		t.Errorf("route %v %v is not documented", route.Method, route.Path)
+	}
+
+	swagger, err := serviceImpl.spec.Build(serviceImpl.echo.Routes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := swagger.Validate(context.Background()); err != nil {
+		t.Errorf("invalid document: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	serviceImpl.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /openapi.json = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestServiceImpl_NoRuntimeControls(t *testing.T) {
+	serviceImpl := New().WithAuthenticator(AuthSchemeBearer, tokenAuthenticator{})
+	services.New().WithHttpServer(serviceImpl)
+	if err := serviceImpl.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the runtime controls are served only by the admin server
+	for _, route := range serviceImpl.echo.Routes() {
+		for _, prefix := range []string{"/admin/log-levels", "/debug", "/metrics", "/config"} {
+			if strings.HasPrefix(route.Path, prefix) {
+				t.Errorf("route %v %v is served on the public server", route.Method, route.Path)
+			}
+		}
+	}
+	// the /admin group authenticates before finding no route
+	rec := httptest.NewRecorder()
+	serviceImpl.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/log-levels", strings.NewReader(`{"level":"trace"}`)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("PUT /admin/log-levels = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+type contractEnvironment struct {
+	services.NoopEnvironment
+}
+
+func (e *contractEnvironment) WithSis(_ services.Sis) services.Environment {
+	return e
+}
+
+func (e *contractEnvironment) OpenAPIValidationEnabled() bool {
+	return true
+}
+
+func TestServiceImpl_ContractAfterAuth(t *testing.T) {
+	serviceImpl := New().WithAuthenticator(AuthSchemeBearer, tokenAuthenticator{
+		"writer": {Subject: "user-2", Roles: []string{RoleUsersWrite}},
+		"reader": {Subject: "user-1", Roles: []string{RoleUsersRead}},
+	})
+	services.New().WithEnvironment(&contractEnvironment{}).WithHttpServer(serviceImpl)
+	if err := serviceImpl.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{name: "anonymous", status: http.StatusUnauthorized},
+		{name: "without role", authorization: "Bearer reader", status: http.StatusForbidden},
+		{name: "with role", authorization: "Bearer writer", status: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/users", strings.NewReader(`{"name":"","email":"john"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.authorization != "" {
+				req.Header.Set(HeaderAuthorization, tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			serviceImpl.echo.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
+
+type corsEnvironment struct {
+	services.NoopEnvironment
+}
+
+func (e *corsEnvironment) WithSis(_ services.Sis) services.Environment {
+	return e
+}
+
+func (e *corsEnvironment) HttpCORSAllowOrigins() []string {
+	return []string{corsAllowAnyOrigin}
+}
+
+func (e *corsEnvironment) HttpCORSAllowCredentials() bool {
+	return true
+}
+
+func TestServiceImpl_InitCORSAnyOriginWithCredentials(t *testing.T) {
+	serviceImpl := New()
+	services.New().WithEnvironment(&corsEnvironment{}).WithHttpServer(serviceImpl)
+	if err := serviceImpl.Init(context.Background()); !goerrors.Is(err, errors.CORSAnyOriginWithCredentials) {
+		t.Errorf("Init() = %v, want %v", err, errors.CORSAnyOriginWithCredentials)
+	}
+}
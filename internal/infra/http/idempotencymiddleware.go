@@ -0,0 +1,165 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	goerrors "errors"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotentReplayed  = "Idempotent-Replayed"
+	idempotencyKeyMaxLength   = 255
+	idempotencyLockTTL        = 30 * time.Second
+	idempotencyCacheKeyPrefix = "idempotency:"
+)
+
+type (
+	idempotentResponse struct {
+		Fingerprint string      `json:"fingerprint"`
+		Status      int         `json:"status"`
+		Header      http.Header `json:"header"`
+		Body        []byte      `json:"body"`
+	}
+	recorderWriter struct {
+		http.ResponseWriter
+		body bytes.Buffer
+	}
+)
+
+func (w *recorderWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// IdempotencyMiddleware replays for ttl the response of the requests retried with the same Idempotency-Key.
+func IdempotencyMiddleware(cache services.Cache, ttl time.Duration) func(h echo.HandlerFunc) echo.HandlerFunc {
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			key := req.Header.Get(HeaderIdempotencyKey)
+			if key == "" || !requiresIdempotencyKey(req.Method) {
+				return h(c)
+			}
+			if len(key) > idempotencyKeyMaxLength {
+				return errors.IdempotencyKeyInvalid
+			}
+
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+			}
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			ctx := req.Context()
+			cacheKey := idempotencyCacheKeyPrefix + idempotencyScope(c) + ":" + key
+			fingerprint := idempotencyFingerprint(req, body)
+			if replayed, err := replayIdempotentResponse(ctx, c, cache, cacheKey, fingerprint); replayed || err != nil {
+				return err
+			}
+
+			lock, err := cache.Lock(ctx, cacheKey, idempotencyLockTTL)
+			if goerrors.Is(err, errors.CacheLockNotAcquired) {
+				return errors.IdempotencyKeyInProgress
+			}
+			if err != nil {
+				return err
+			}
+			defer func() {
+				_ = lock.Release(context.Background())
+			}()
+
+			// the first request may have finished between the lookup and the lock
+			if replayed, err := replayIdempotentResponse(ctx, c, cache, cacheKey, fingerprint); replayed || err != nil {
+				return err
+			}
+
+			recorder := &recorderWriter{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			if err := h(c); err != nil {
+				// commit the error response now so it is recorded as well
+				c.Error(err)
+			}
+			c.Response().Writer = recorder.ResponseWriter
+
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				return nil
+			}
+			stored, err := json.Marshal(&idempotentResponse{
+				Fingerprint: fingerprint,
+				Status:      status,
+				Header:      c.Response().Header().Clone(),
+				Body:        recorder.body.Bytes(),
+			})
+			if err != nil {
+				return nil
+			}
+			_ = cache.Set(ctx, cacheKey, stored, ttl)
+			return nil
+		}
+	}
+}
+
+func replayIdempotentResponse(ctx context.Context, c echo.Context, cache services.Cache, cacheKey, fingerprint string) (bool, error) {
+	raw, err := cache.Get(ctx, cacheKey)
+	if goerrors.Is(err, errors.CacheKeyNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	stored := new(idempotentResponse)
+	if err := json.Unmarshal(raw, stored); err != nil {
+		return false, err
+	}
+	if stored.Fingerprint != fingerprint {
+		return true, errors.IdempotencyKeyReused
+	}
+
+	// the headers set by the middlewares before this one, as the cid, are the ones of this request
+	header := c.Response().Header()
+	for k, values := range stored.Header {
+		if _, ok := header[k]; !ok {
+			header[k] = values
+		}
+	}
+	c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+	c.Response().WriteHeader(stored.Status)
+	_, err = c.Response().Write(stored.Body)
+	return true, err
+}
+
+// idempotencyScope keeps the keys of a caller from replaying the responses of another one.
+func idempotencyScope(c echo.Context) string {
+	var scope string
+	if principal := ctxs.GetPrincipalFromContext(c.Request().Context()); principal != nil {
+		scope = "principal\x00" + principal.Tenant + "\x00" + principal.Subject
+	} else {
+		scope = "ip\x00" + c.RealIP()
+	}
+	sum := sha256.Sum256([]byte(scope))
+	return hex.EncodeToString(sum[:])
+}
+
+func idempotencyFingerprint(req *http.Request, body []byte) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, req.Method+" "+req.URL.Path+"\n")
+	_, _ = h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func requiresIdempotencyKey(method string) bool {
+	return method == http.MethodPost || method == http.MethodPatch || method == http.MethodDelete
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/infra/cache"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	calls := 0
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(services.NewNoopLogger())
+	e.POST("/users", func(c echo.Context) error {
+		calls++
+		return c.String(http.StatusCreated, "created")
+	}, IdempotencyMiddleware(cache.NewLRU(), time.Minute))
+
+	do := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+		req.Header.Set(HeaderIdempotencyKey, "key-1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(`{"name":"a"}`); rec.Code != http.StatusCreated || rec.Body.String() != "created" {
+		t.Errorf("first request = %d %s", rec.Code, rec.Body.String())
+	}
+	rec := do(`{"name":"a"}`)
+	if rec.Code != http.StatusCreated || rec.Body.String() != "created" || rec.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Errorf("retry = %d %s, want replayed response", rec.Code, rec.Body.String())
+	}
+	if rec := do(`{"name":"b"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1", calls)
+	}
+}
+
+func TestIdempotencyMiddleware_Scope(t *testing.T) {
+	calls := 0
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(services.NewNoopLogger())
+	principals := func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if subject := c.Request().Header.Get("X-Subject"); subject != "" {
+				withPrincipal(c, &structs.Principal{Subject: subject})
+			}
+			return h(c)
+		}
+	}
+	e.POST("/users", func(c echo.Context) error {
+		calls++
+		return c.String(http.StatusCreated, strconv.Itoa(calls))
+	}, CidMiddleware(), principals, IdempotencyMiddleware(cache.NewLRU(), time.Minute))
+
+	do := func(subject, cid string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"a"}`))
+		req.Header.Set(HeaderIdempotencyKey, "key-1")
+		req.Header.Set(HeaderCorrelationID, cid)
+		if subject != "" {
+			req.Header.Set("X-Subject", subject)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	do("user-1", "cid-1")
+	if rec := do("user-2", "cid-2"); rec.Body.String() != "2" || rec.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Errorf("other principal = %s, want its own response", rec.Body.String())
+	}
+	if rec := do("", "cid-3"); rec.Body.String() != "3" {
+		t.Errorf("anonymous = %s, want its own response", rec.Body.String())
+	}
+	rec := do("user-1", "cid-4")
+	if rec.Body.String() != "1" || rec.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Errorf("retry = %s, want the replayed response", rec.Body.String())
+	}
+	if cids := rec.Header().Values(HeaderCorrelationID); len(cids) != 1 || cids[0] != "cid-4" {
+		t.Errorf("cid = %v, want only the one of the retry", cids)
+	}
+}
+
+func TestIdempotencyMiddleware_ForwardedFor(t *testing.T) {
+	calls := 0
+	e := NewEcho(services.NewNoopLogger())
+	e.POST("/users", func(c echo.Context) error {
+		calls++
+		return c.String(http.StatusCreated, strconv.Itoa(calls))
+	}, IdempotencyMiddleware(cache.NewLRU(), time.Minute))
+
+	do := func(remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"a"}`))
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(HeaderIdempotencyKey, "key-1")
+		req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	do("198.51.100.1:1234", "203.0.113.1")
+	if rec := do("198.51.100.2:1234", "203.0.113.1"); rec.Body.String() != "2" || rec.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Errorf("other client with the same X-Forwarded-For = %s, want its own response", rec.Body.String())
+	}
+	if rec := do("198.51.100.1:1234", "203.0.113.2"); rec.Body.String() != "1" || rec.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Errorf("same client with another X-Forwarded-For = %s, want the replayed response", rec.Body.String())
+	}
+}
+
+func TestIdempotencyMiddleware_InProgress(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(services.NewNoopLogger())
+	e.POST("/users", func(c echo.Context) error {
+		close(entered)
+		<-release
+		return c.String(http.StatusCreated, "created")
+	}, IdempotencyMiddleware(cache.NewLRU(), time.Minute))
+
+	do := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"a"}`))
+		req.Header.Set(HeaderIdempotencyKey, "key-1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() {
+		first <- do()
+	}()
+	<-entered
+	if rec := do(); rec.Code != http.StatusConflict {
+		t.Errorf("retry while running = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	close(release)
+	if rec := <-first; rec.Code != http.StatusCreated {
+		t.Errorf("first request = %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/labstack/echo/v4"
+	"net"
+)
+
+// ipExtractor reads X-Forwarded-For only behind the trusted proxies, else the address of the connection.
+func ipExtractor(trusted []string) (echo.IPExtractor, error) {
+	if len(trusted) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range trusted {
+		_, ipRange, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", errors.HttpTrustedProxyInvalid, cidr)
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
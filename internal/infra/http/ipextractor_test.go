@@ -0,0 +1,42 @@
+package http
+
+import (
+	goerrors "errors"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIpExtractor(t *testing.T) {
+	tests := []struct {
+		name       string
+		trusted    []string
+		remoteAddr string
+		want       string
+		wantErr    error
+	}{
+		{name: "direct", remoteAddr: "198.51.100.1:1234", want: "198.51.100.1"},
+		{name: "loopback not trusted", trusted: []string{"192.0.2.0/24"}, remoteAddr: "127.0.0.1:1234", want: "127.0.0.1"},
+		{name: "trusted proxy", trusted: []string{"192.0.2.0/24"}, remoteAddr: "192.0.2.1:1234", want: "203.0.113.2"},
+		{name: "invalid cidr", trusted: []string{"192.0.2.1"}, wantErr: errors.HttpTrustedProxyInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extractor, err := ipExtractor(tt.trusted)
+			if !goerrors.Is(err, tt.wantErr) {
+				t.Fatalf("ipExtractor() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.1, 203.0.113.2")
+			if got := extractor(req); got != tt.want {
+				t.Errorf("ip = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
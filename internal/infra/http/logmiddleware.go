@@ -2,33 +2,52 @@ package http
 
 import (
 	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
 	"github.com/dalmarcogd/bpl-go/internal/services"
 	"github.com/labstack/echo/v4"
 	"net/http"
 )
 
+// LogMiddleware logs each request with a child of log put on the request context.
 func LogMiddleware(log services.Logger) func(h echo.HandlerFunc) echo.HandlerFunc {
 	return func(h echo.HandlerFunc) echo.HandlerFunc {
 		return func(context echo.Context) error {
-			ctx := context.Request().Context()
-			log.Info(ctx, fmt.Sprintf("Request %v:%v", context.Request().Method, context.Path()))
+			reqLog := log.With(map[string]interface{}{
+				"route":  context.Path(),
+				"method": context.Request().Method,
+			})
+			ctx := ctxs.ContextWithLogger(context.Request().Context(), reqLog)
+			context.SetRequest(context.Request().WithContext(ctx))
+
+			reqLog.Info(ctx, fmt.Sprintf("Request %v:%v", context.Request().Method, context.Path()))
 			err := h(context)
-			status := context.Response().Status
-			if err != nil {
-				status = http.StatusInternalServerError
-				he, ok := err.(*echo.HTTPError)
-				if ok {
-					if he.Internal != nil {
-						if herr, ok := he.Internal.(*echo.HTTPError); ok {
-							he = herr
-						}
-					}
-					status = he.Code
-				}
+			// the auth middlewares and handlers may have bound more fields, as the authenticated user
+			if handlerLog := ctxs.GetLoggerFromContext(context.Request().Context()); handlerLog != nil {
+				reqLog = handlerLog
 			}
-			log.Info(ctx, fmt.Sprintf("Response %v:%v:%v", context.Request().Method, context.Path(), status))
+			status := responseStatus(context, err)
+			reqLog.Info(ctx, fmt.Sprintf("Response %v:%v:%v", context.Request().Method, context.Path(), status))
 
 			return err
 		}
 	}
 }
+
+// responseStatus is the status the client gets, err is not handled yet.
+func responseStatus(c echo.Context, err error) int {
+	if err == nil {
+		return c.Response().Status
+	}
+	if e, ok := errors.As(err); ok {
+		return e.Status
+	}
+	he, ok := err.(*echo.HTTPError)
+	if !ok {
+		return http.StatusInternalServerError
+	}
+	if herr, ok := he.Internal.(*echo.HTTPError); ok {
+		he = herr
+	}
+	return he.Code
+}
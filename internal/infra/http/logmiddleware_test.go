@@ -1 +1,75 @@
 package http
+
+import (
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fieldsLogger records the bound fields of each entry logged through it or its children.
+type fieldsLogger struct {
+	services.NoopLogger
+	fields  map[string]interface{}
+	entries *[]map[string]interface{}
+}
+
+func (l *fieldsLogger) With(fields map[string]interface{}) services.Logger {
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &fieldsLogger{fields: merged, entries: l.entries}
+}
+
+func (l *fieldsLogger) Info(_ context.Context, _ string, _ ...map[string]interface{}) {
+	*l.entries = append(*l.entries, l.fields)
+}
+
+func TestLogMiddleware(t *testing.T) {
+	var entries []map[string]interface{}
+	log := &fieldsLogger{entries: &entries}
+	e := echo.New()
+	e.GET("/users", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, LogMiddleware(log))
+	e.GET("/me", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, LogMiddleware(log), func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			withPrincipal(c, &structs.Principal{Subject: "user-1", Tenant: "acme"})
+			return h(c)
+		}
+	})
+
+	// the tenant header of an anonymous request is not trusted
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("X-Tenant-ID", "forged")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	if len(entries) != 2 {
+		t.Fatalf("entries = %v, want the request and the response", len(entries))
+	}
+	for _, entry := range entries {
+		if _, ok := entry["tenant"]; ok {
+			t.Errorf("tenant = %v, want none without a principal", entry["tenant"])
+		}
+		if entry["route"] != "/users" || entry["method"] != http.MethodGet {
+			t.Errorf("route, method = %v, %v", entry["route"], entry["method"])
+		}
+	}
+
+	entries = entries[:0]
+	req = httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("X-Tenant-ID", "forged")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	response := entries[len(entries)-1]
+	if response["user_id"] != "user-1" || response["tenant"] != "acme" {
+		t.Errorf("user_id, tenant = %v, %v, want those of the principal", response["user_id"], response["tenant"])
+	}
+}
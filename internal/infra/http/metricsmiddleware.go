@@ -0,0 +1,54 @@
+package http
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// routeUnmatched and methodOther keep unknown routes and methods from creating series.
+	routeUnmatched = "unmatched"
+	methodOther    = "OTHER"
+)
+
+// MetricsMiddleware records the rate, errors and duration of the requests and the ones in flight.
+func MetricsMiddleware(metrics services.Metrics) func(h echo.HandlerFunc) echo.HandlerFunc {
+	requests := metrics.Counter("http_requests_total", "Requests served by route, method and status.", "route", "method", "status")
+	duration := metrics.Histogram("http_request_duration_seconds", "Duration of the requests by route, method and status.", nil, "route", "method", "status")
+	inFlight := metrics.Gauge("http_requests_in_flight", "Requests being served.")
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			inFlight.Add(1)
+			defer inFlight.Add(-1)
+			start := time.Now()
+			err := h(c)
+
+			// echo sets the path of unknown routes to the request one
+			route := c.Path()
+			if route == "" || err == echo.ErrNotFound || err == echo.ErrMethodNotAllowed {
+				route = routeUnmatched
+			}
+			method := c.Request().Method
+			if !knownMethod(method) {
+				method = methodOther
+			}
+			status := strconv.Itoa(responseStatus(c, err))
+			requests.Inc(route, method, status)
+			duration.Observe(time.Since(start).Seconds(), route, method, status)
+			return err
+		}
+	}
+}
+
+func knownMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
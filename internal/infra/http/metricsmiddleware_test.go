@@ -0,0 +1,44 @@
+package http
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/metrics"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	m := metrics.New()
+	e := echo.New()
+	e.Use(MetricsMiddleware(m))
+	e.GET("/users/:userId", func(c echo.Context) error {
+		if c.Param("userId") == "missing" {
+			return errors.UserNotFound
+		}
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, path := range []string{"/users/1", "/users/2", "/users/missing", "/unknown"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`http_requests_total{method="GET",route="/users/:userId",status="200"} 2`,
+		`http_requests_total{method="GET",route="/users/:userId",status="404"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="/users/:userId",status="200"} 2`,
+		`http_requests_in_flight 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics have no %q", want)
+		}
+	}
+	if strings.Contains(body, `route="/unknown"`) {
+		t.Error("unknown paths create series")
+	}
+}
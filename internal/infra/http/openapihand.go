@@ -0,0 +1,14 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+func (s *ServiceImpl) handleGetOpenAPI(c echo.Context) error {
+	swagger, err := s.spec.Build(s.echo.Routes())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, swagger)
+}
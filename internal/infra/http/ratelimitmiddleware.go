@@ -0,0 +1,129 @@
+package http
+
+import (
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/cache"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/labstack/echo/v4"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+
+	RateLimitKeyIP        = "ip"
+	RateLimitKeyPrincipal = "principal"
+	RateLimitKeyTenant    = "tenant"
+)
+
+// RateLimitKeyFunc returns who the request is accounted to.
+type RateLimitKeyFunc func(c echo.Context) string
+
+func RateLimitByIP(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+// RateLimitByPrincipal accounts requests to the authenticated principal, anonymous requests by IP.
+func RateLimitByPrincipal(c echo.Context) string {
+	if principal := ctxs.GetPrincipalFromContext(c.Request().Context()); principal != nil {
+		return "principal:" + principal.Tenant + ":" + principal.Subject
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimitByTenant accounts requests to the tenant of the authenticated principal, anonymous ones by IP.
+func RateLimitByTenant(c echo.Context) string {
+	if principal := ctxs.GetPrincipalFromContext(c.Request().Context()); principal != nil && principal.Tenant != "" {
+		return "tenant:" + principal.Tenant
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimitKeyFuncOf returns the RateLimitKeyFunc of name.
+func RateLimitKeyFuncOf(name string) RateLimitKeyFunc {
+	switch name {
+	case RateLimitKeyPrincipal:
+		return RateLimitByPrincipal
+	case RateLimitKeyTenant:
+		return RateLimitByTenant
+	default:
+		return RateLimitByIP
+	}
+}
+
+// ParseRouteRateLimits reads limits written as METHOD /path=limit/period_ms by METHOD /path.
+func ParseRouteRateLimits(routes []string, base structs.RateLimit) (map[string]structs.RateLimit, error) {
+	limits := make(map[string]structs.RateLimit, len(routes))
+	for _, route := range routes {
+		i := strings.LastIndexByte(route, '=')
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %q", errors.RateLimitRouteInvalid, route)
+		}
+		fields := strings.Fields(route[:i])
+		values := strings.Split(route[i+1:], "/")
+		if len(fields) != 2 || len(values) != 2 {
+			return nil, fmt.Errorf("%w: %q", errors.RateLimitRouteInvalid, route)
+		}
+		limit, errL := strconv.Atoi(values[0])
+		periodMs, errP := strconv.Atoi(values[1])
+		if errL != nil || errP != nil {
+			return nil, fmt.Errorf("%w: %q", errors.RateLimitRouteInvalid, route)
+		}
+		routeLimit := structs.RateLimit{
+			Algorithm: base.Algorithm,
+			Limit:     limit,
+			Period:    time.Duration(periodMs) * time.Millisecond,
+		}
+		if err := routeLimit.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %q", err, route)
+		}
+		limits[strings.ToUpper(fields[0])+" "+fields[1]] = routeLimit
+	}
+	return limits, nil
+}
+
+// RateLimitMiddleware limits each key to limit, or to the limit of its route in routes.
+func RateLimitMiddleware(limiter services.Cache, log services.Logger, limit structs.RateLimit, routes map[string]structs.RateLimit, key RateLimitKeyFunc) func(h echo.HandlerFunc) echo.HandlerFunc {
+	fallback := cache.NewLRU()
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			routeLimit, ok := routes[c.Request().Method+" "+c.Path()]
+			if !ok {
+				routeLimit = limit
+			}
+			bucket := c.Request().Method + ":" + c.Path() + ":" + key(c)
+			result, err := limiter.RateLimit(ctx, bucket, routeLimit)
+			if err != nil {
+				log.Warn(ctx, "Rate limit counted in memory, the limiter failed", map[string]interface{}{"error": err})
+				result, err = fallback.RateLimit(ctx, bucket, routeLimit)
+				if err != nil {
+					return err
+				}
+			}
+
+			header := c.Response().Header()
+			header.Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+			header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+			header.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.ResetAfter)))
+			if !result.Allowed {
+				header.Set(HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				return errors.RateLimitExceeded
+			}
+			return h(c)
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
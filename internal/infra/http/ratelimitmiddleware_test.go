@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+	goerrors "errors"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/cache"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type failingLimiter struct {
+	services.NoopCache
+}
+
+func (l *failingLimiter) RateLimit(_ context.Context, _ string, _ structs.RateLimit) (*structs.RateLimitResult, error) {
+	return nil, goerrors.New("connection refused")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	limit := structs.RateLimit{Algorithm: structs.RateLimitSlidingWindow, Limit: 2, Period: time.Minute}
+	routes, err := ParseRouteRateLimits([]string{"post /users=1/60000"}, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, limiter := range map[string]services.Cache{"cache": cache.NewLRU(), "failing cache": &failingLimiter{}} {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = ErrorHandler(services.NewNoopLogger())
+			principals := func(h echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					if subject := c.Request().Header.Get("X-Subject"); subject != "" {
+						withPrincipal(c, &structs.Principal{Subject: subject})
+					}
+					return h(c)
+				}
+			}
+			rateLimit := RateLimitMiddleware(limiter, services.NewNoopLogger(), limit, routes, RateLimitByPrincipal)
+			ok := func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}
+			e.GET("/users", ok, principals, rateLimit)
+			e.POST("/users", ok, principals, rateLimit)
+
+			do := func(method, subject string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, "/users", nil)
+				req.Header.Set("X-Subject", subject)
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+				return rec
+			}
+
+			for i := 0; i < 2; i++ {
+				if rec := do(http.MethodGet, "user-1"); rec.Code != http.StatusOK {
+					t.Errorf("request %d = %d, want %d", i, rec.Code, http.StatusOK)
+				}
+			}
+			rec := do(http.MethodGet, "user-1")
+			if rec.Code != http.StatusTooManyRequests {
+				t.Errorf("over the limit = %d, want %d", rec.Code, http.StatusTooManyRequests)
+			}
+			header := rec.Header()
+			if header.Get(HeaderRateLimitLimit) != "2" || header.Get(HeaderRateLimitRemaining) != "0" ||
+				header.Get(HeaderRateLimitReset) != "60" || header.Get(HeaderRetryAfter) != "60" {
+				t.Errorf("headers = %v", header)
+			}
+
+			// principals are accounted apart, whatever the headers they send
+			if rec := do(http.MethodGet, "user-2"); rec.Code != http.StatusOK {
+				t.Errorf("other principal = %d, want %d", rec.Code, http.StatusOK)
+			}
+			// the limit of the route overrides the default one
+			do(http.MethodPost, "user-1")
+			if rec := do(http.MethodPost, "user-1"); rec.Code != http.StatusTooManyRequests || rec.Header().Get(HeaderRateLimitLimit) != "1" {
+				t.Errorf("over the route limit = %d %v", rec.Code, rec.Header())
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware_ForwardedFor(t *testing.T) {
+	limit := structs.RateLimit{Algorithm: structs.RateLimitSlidingWindow, Limit: 1, Period: time.Minute}
+	tests := []struct {
+		name    string
+		trusted []string
+		status  int
+	}{
+		{name: "spoofed", status: http.StatusTooManyRequests},
+		{name: "trusted proxy", trusted: []string{"192.0.2.0/24"}, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEcho(services.NewNoopLogger())
+			extractor, err := ipExtractor(tt.trusted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e.IPExtractor = extractor
+			e.GET("/users", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}, RateLimitMiddleware(cache.NewLRU(), services.NewNoopLogger(), limit, nil, RateLimitByIP))
+
+			// the requests come from the same connection address, 192.0.2.1
+			do := func(forwardedFor string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, "/users", nil)
+				req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+				req.Header.Set(echo.HeaderXRealIP, forwardedFor)
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+				return rec
+			}
+			do("203.0.113.1")
+			if rec := do("203.0.113.2"); rec.Code != tt.status {
+				t.Errorf("other X-Forwarded-For = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestParseRouteRateLimits(t *testing.T) {
+	for _, route := range []string{"/v1/users=1/1000", "POST /v1/users", "POST /v1/users=1", "POST /v1/users=a/1000", "POST /v1/users=1/0", "POST /v1/users=0/1000"} {
+		if _, err := ParseRouteRateLimits([]string{route}, structs.RateLimit{}); err == nil {
+			t.Errorf("ParseRouteRateLimits(%q) error = nil", route)
+		}
+	}
+	limits, err := ParseRouteRateLimits([]string{"PATCH /v1/users/:userId=5/1000"}, structs.RateLimit{Algorithm: structs.RateLimitTokenBucket})
+	want := structs.RateLimit{Algorithm: structs.RateLimitTokenBucket, Limit: 5, Period: time.Second}
+	if err != nil || limits["PATCH /v1/users/:userId"] != want {
+		t.Errorf("ParseRouteRateLimits() = %v, %v", limits, err)
+	}
+	if err := (structs.RateLimit{Limit: 1}).Validate(); !goerrors.Is(err, errors.RateLimitInvalid) {
+		t.Errorf("Validate() error = %v, want %v", err, errors.RateLimitInvalid)
+	}
+}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"runtime/debug"
+)
+
+// RecoverMiddleware turns a panic of the next handlers into errors.InternalError.
+func RecoverMiddleware(log services.Logger) func(h echo.HandlerFunc) echo.HandlerFunc {
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+				ctx := c.Request().Context()
+				reqLog := log
+				if l := ctxs.GetLoggerFromContext(ctx); l != nil {
+					reqLog = l
+				}
+				reqLog.Error(ctx, fmt.Sprintf("Request %v:%v panicked", c.Request().Method, c.Path()), map[string]interface{}{
+					"panic": fmt.Sprint(r),
+					"stack": string(debug.Stack()),
+				})
+				err = errors.InternalError.Wrap(fmt.Errorf("panic: %v", r))
+			}()
+			return h(c)
+		}
+	}
+}
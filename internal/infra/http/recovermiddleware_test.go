@@ -0,0 +1,37 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(services.NewNoopLogger())
+	e.GET("/users", func(c echo.Context) error {
+		var users map[string]string
+		users["1"] = "nil map"
+		return nil
+	}, CidMiddleware(), RecoverMiddleware(services.NewNoopLogger()))
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(HeaderCorrelationID, "cid-1")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %v, want %v", rec.Code, http.StatusInternalServerError)
+	}
+	problem := new(models.Problem)
+	if err := json.Unmarshal(rec.Body.Bytes(), problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Cid != "cid-1" || problem.Detail == "nil map" {
+		t.Errorf("problem = %+v, want the cid without the panic", problem)
+	}
+}
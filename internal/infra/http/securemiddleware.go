@@ -0,0 +1,33 @@
+package http
+
+import (
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/labstack/echo/v4"
+)
+
+// SecureHeadersMiddleware sets the security headers on every response, the HSTS only over https.
+func SecureHeadersMiddleware(headers structs.SecureHeaders) func(h echo.HandlerFunc) echo.HandlerFunc {
+	hsts := ""
+	if headers.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", int64(headers.HSTSMaxAge.Seconds()))
+		if headers.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+			if headers.ContentTypeNosniff {
+				header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+			}
+			if headers.FrameOptions != "" {
+				header.Set(echo.HeaderXFrameOptions, headers.FrameOptions)
+			}
+			if hsts != "" && c.Scheme() == "https" {
+				header.Set(echo.HeaderStrictTransportSecurity, hsts)
+			}
+			return h(c)
+		}
+	}
+}
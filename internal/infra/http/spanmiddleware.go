@@ -0,0 +1,81 @@
+package http
+
+import (
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/labstack/echo/v4"
+	"github.com/openzipkin/zipkin-go"
+	"github.com/openzipkin/zipkin-go/model"
+	"github.com/openzipkin/zipkin-go/propagation"
+	"github.com/openzipkin/zipkin-go/propagation/b3"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const HeaderTraceParent = "traceparent"
+
+// SpanMiddleware puts a server span of each request, child of its B3 or W3C headers, on the context.
+func SpanMiddleware(tracer *zipkin.Tracer) func(h echo.HandlerFunc) echo.HandlerFunc {
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		if tracer == nil {
+			return h
+		}
+		return func(c echo.Context) error {
+			req := c.Request()
+			parent := tracer.Extract(extractHTTP(req))
+			span := tracer.StartSpan(
+				fmt.Sprintf("%v %v", req.Method, c.Path()),
+				zipkin.Kind(model.Server),
+				zipkin.Parent(parent),
+			)
+			defer span.Finish()
+
+			zipkin.TagHTTPMethod.Set(span, req.Method)
+			zipkin.TagHTTPRoute.Set(span, c.Path())
+			if cid := ctxs.GetCidFromContext(req.Context()); cid != nil {
+				span.Tag("cid", *cid)
+			}
+			c.SetRequest(req.WithContext(zipkin.NewContext(req.Context(), span)))
+
+			err := h(c)
+			status := responseStatus(c, err)
+			zipkin.TagHTTPStatusCode.Set(span, strconv.Itoa(status))
+			if status >= http.StatusInternalServerError {
+				zipkin.TagError.Set(span, http.StatusText(status))
+			}
+			return err
+		}
+	}
+}
+
+func extractHTTP(req *http.Request) propagation.Extractor {
+	return func() (*model.SpanContext, error) {
+		sc, err := b3.ExtractHTTP(req)()
+		if err == nil && sc != nil && !sc.TraceID.Empty() {
+			return sc, nil
+		}
+		if traceParent := req.Header.Get(HeaderTraceParent); traceParent != "" {
+			return parseTraceParent(traceParent)
+		}
+		// B3 may carry only a sampling decision
+		return sc, err
+	}
+}
+
+// parseTraceParent parses version 00 of the W3C header.
+func parseTraceParent(traceParent string) (*model.SpanContext, error) {
+	parts := strings.Split(traceParent, "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return nil, errors.TraceParentInvalid
+	}
+	traceID, errT := model.TraceIDFromHex(parts[1])
+	id, errI := strconv.ParseUint(parts[2], 16, 64)
+	flags, errF := strconv.ParseUint(parts[3], 16, 8)
+	if errT != nil || errI != nil || errF != nil || traceID.Empty() || id == 0 {
+		return nil, errors.TraceParentInvalid
+	}
+	sampled := flags&1 == 1
+	return &model.SpanContext{TraceID: traceID, ID: model.ID(id), Sampled: &sampled}, nil
+}
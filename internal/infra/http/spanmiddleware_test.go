@@ -0,0 +1,60 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/openzipkin/zipkin-go"
+	"github.com/openzipkin/zipkin-go/propagation/b3"
+	"github.com/openzipkin/zipkin-go/reporter/recorder"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSpanMiddleware(t *testing.T) {
+	reporter := recorder.NewReporter()
+	tracer, err := zipkin.NewTracer(reporter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	e.GET("/users/:userId", func(c echo.Context) error {
+		if zipkin.SpanFromContext(c.Request().Context()) == nil {
+			t.Error("expected the server span on the context")
+		}
+		return c.NoContent(http.StatusNotFound)
+	}, CidMiddleware(), SpanMiddleware(tracer))
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		traceID string
+	}{
+		{name: "b3", headers: map[string]string{b3.TraceID: "80f198ee56343ba864fe8b2a57d3eff7", b3.SpanID: "e457b5a2e4d86bd1"}, traceID: "80f198ee56343ba864fe8b2a57d3eff7"},
+		{name: "w3c", headers: map[string]string{HeaderTraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}, traceID: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{name: "invalid-w3c", headers: map[string]string{HeaderTraceParent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			e.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := reporter.Flush()
+			if len(spans) != 1 {
+				t.Fatalf("reported %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if tt.traceID != "" && span.TraceID.String() != tt.traceID {
+				t.Errorf("trace id = %v, want %v", span.TraceID, tt.traceID)
+			}
+			if tt.traceID == "" && span.ParentID != nil {
+				t.Errorf("parent id = %v, want a new trace", span.ParentID)
+			}
+			if span.Tags["http.route"] != "/users/:userId" || span.Tags["http.status_code"] != "404" || span.Tags["cid"] == "" {
+				t.Errorf("tags = %v", span.Tags)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tlsClientAuthRequire  = "require"
+	tlsClientAuthOptional = "optional"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+type (
+	// certReloader reloads the changed files once per interval, keeping the certificate when they fail to load.
+	certReloader struct {
+		certFile  string
+		keyFile   string
+		interval  time.Duration
+		log       services.Logger
+		ctx       context.Context
+		mu        sync.RWMutex
+		cert      *tls.Certificate
+		modTime   time.Time
+		checkedAt time.Time
+	}
+)
+
+func newCertReloader(ctx context.Context, log services.Logger, certFile, keyFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+		log:      log,
+		ctx:      ctx,
+	}
+	modTime, err := r.modified()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	r.checkedAt = time.Now()
+	return r, nil
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	cert, checkedAt := r.cert, r.checkedAt
+	r.mu.RUnlock()
+	if time.Since(checkedAt) < r.interval {
+		return cert, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.checkedAt) < r.interval {
+		return r.cert, nil
+	}
+	r.checkedAt = time.Now()
+	modTime, err := r.modified()
+	if err != nil || !modTime.After(r.modTime) {
+		return r.cert, nil
+	}
+	reloaded, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		r.log.Warn(r.ctx, "Tls certificate not reloaded", map[string]interface{}{"error": err})
+		return r.cert, nil
+	}
+	r.cert = &reloaded
+	r.modTime = modTime
+	r.log.Info(r.ctx, "Tls certificate reloaded")
+	return r.cert, nil
+}
+
+func (r *certReloader) modified() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// newTLSConfig is nil when no certificate is configured.
+func newTLSConfig(ctx context.Context, log services.Logger, env services.Environment) (*tls.Config, error) {
+	if env.HttpTLSCertFile() == "" && env.HttpTLSKeyFile() == "" {
+		return nil, nil
+	}
+	if env.HttpTLSCertFile() == "" || env.HttpTLSKeyFile() == "" {
+		return nil, errors.TLSKeyPairRequired
+	}
+	reloader, err := newCertReloader(ctx, log, env.HttpTLSCertFile(), env.HttpTLSKeyFile(), env.HttpTLSReloadInterval())
+	if err != nil {
+		return nil, err
+	}
+	minVersion, ok := tlsVersions[env.HttpTLSMinVersion()]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", errors.TLSMinVersionUnsupported, env.HttpTLSMinVersion())
+	}
+	ciphers, err := cipherSuites(env.HttpTLSCiphers())
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		MinVersion:     minVersion,
+		CipherSuites:   ciphers,
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     []string{"http/1.1"},
+	}
+	if env.HttpHTTP2() {
+		config.NextProtos = []string{"h2", "http/1.1"}
+	}
+
+	if env.HttpTLSClientCAFile() != "" {
+		pool, err := certPool(env.HttpTLSClientCAFile())
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		switch env.HttpTLSClientAuth() {
+		case tlsClientAuthRequire:
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		case tlsClientAuthOptional:
+			config.ClientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, fmt.Errorf("%w: %q", errors.TLSClientAuthUnsupported, env.HttpTLSClientAuth())
+		}
+	}
+	return config, nil
+}
+
+// cipherSuites refuses the insecure suites, no names keeps the go defaults.
+func cipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", errors.TLSCipherUnsupported, name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func certPool(file string) (*x509.CertPool, error) {
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(raw) {
+		return nil, fmt.Errorf("%w: %v", errors.TLSClientCAInvalid, file)
+	}
+	return pool, nil
+}
+
+// redirectHandler answers 308 so the method and body are kept.
+func redirectHandler(httpsAddress string) http.Handler {
+	_, port, _ := net.SplitHostPort(httpsAddress)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = strings.TrimSuffix(strings.TrimPrefix(r.Host, "["), "]")
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pair tls.Certificate
+}
+
+// newTestCert issues a certificate of subject signed by parent, self signed when parent is nil.
+func newTestCert(t *testing.T, subject pkix.Name, parent *testCert, uris ...string) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               subject,
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	for _, u := range uris {
+		parsed, _ := url.Parse(u)
+		template.URIs = append(template.URIs, parsed)
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(raw)
+	return &testCert{cert: cert, key: key, pair: tls.Certificate{Certificate: [][]byte{raw}, PrivateKey: key}}
+}
+
+func (c *testCert) write(t *testing.T, certFile, keyFile string) {
+	keyRaw, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyRaw})
+	if err := ioutil.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if keyFile != "" {
+		if err := ioutil.WriteFile(keyFile, keyPEM, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCertReloader_GetCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	first := newTestCert(t, pkix.Name{CommonName: "first"}, nil)
+	first.write(t, certFile, keyFile)
+
+	reloader, err := newCertReloader(context.Background(), services.NewNoopLogger(), certFile, keyFile, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject := func() string {
+		cert, _ := reloader.GetCertificate(nil)
+		parsed, _ := x509.ParseCertificate(cert.Certificate[0])
+		return parsed.Subject.CommonName
+	}
+
+	second := newTestCert(t, pkix.Name{CommonName: "second"}, nil)
+	second.write(t, certFile, keyFile)
+	if got := subject(); got != "first" {
+		t.Errorf("certificate = %v before the interval, want first", got)
+	}
+
+	// a certificate without its key yet keeps the loaded pair
+	third := newTestCert(t, pkix.Name{CommonName: "third"}, nil)
+	third.write(t, certFile, "")
+	reloader.checkedAt = time.Time{}
+	if got := subject(); got != "first" {
+		t.Errorf("certificate = %v with a mismatched key, want first", got)
+	}
+
+	second.write(t, certFile, keyFile)
+	reloader.checkedAt = time.Time{}
+	reloader.modTime = time.Time{}
+	if got := subject(); got != "second" {
+		t.Errorf("certificate = %v after the interval, want second", got)
+	}
+}
+
+func TestClientCertMiddleware(t *testing.T) {
+	ca := newTestCert(t, pkix.Name{CommonName: "ca"}, nil)
+	serverCert := newTestCert(t, pkix.Name{CommonName: "localhost"}, ca)
+	clientCert := newTestCert(t, pkix.Name{CommonName: "billing", OrganizationalUnit: []string{RoleUsersRead}, Organization: []string{"acme"}}, ca, "spiffe://bpl/billing")
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(services.NewNoopLogger())
+	e.GET("/users", func(c echo.Context) error {
+		principal := ctxs.GetPrincipalFromContext(c.Request().Context())
+		return c.String(http.StatusOK, principal.Subject+" "+principal.Tenant)
+	}, ClientCertMiddleware(), AuthMiddleware(nil), RequireRoles(RoleUsersRead))
+	server := httptest.NewUnstartedServer(e)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert.pair},
+		ClientCAs:    pool,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	get := func(certs ...tls.Certificate) *http.Response {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, Certificates: certs}}}
+		resp, err := client.Get(server.URL + "/users")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := get(clientCert.pair)
+	body, _ := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "spiffe://bpl/billing acme" {
+		t.Errorf("with a client certificate = %v %q", resp.StatusCode, body)
+	}
+	resp = get()
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("without a client certificate = %v, want %v", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	redirectHandler(":8443").ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "http://api.example.com:8080/v1/users?page=2", nil))
+	if rec.Code != http.StatusPermanentRedirect {
+		t.Errorf("status = %v, want %v", rec.Code, http.StatusPermanentRedirect)
+	}
+	if location := rec.Header().Get("Location"); location != "https://api.example.com:8443/v1/users?page=2" {
+		t.Errorf("location = %v", location)
+	}
+}
@@ -10,7 +10,7 @@ import (
 func (s *ServiceImpl) handleCreateUser(c echo.Context) error {
 	uReq := new(models.UserRequest)
 	if err := c.Bind(uReq); err != nil {
-		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error()).SetInternal(err)
+		return err
 	}
 	user := models.User{
 		Name:  uReq.Name,
@@ -18,7 +18,7 @@ func (s *ServiceImpl) handleCreateUser(c echo.Context) error {
 	}
 	err := s.Sis().Handlers().CreateUser(c.Request().Context(), &user)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, &models.UserResponse{
@@ -31,20 +31,20 @@ func (s *ServiceImpl) handleCreateUser(c echo.Context) error {
 func (s *ServiceImpl) handleUpdateUser(c echo.Context) error {
 	userId := c.Param("userId")
 	if userId == "" {
-		return echo.NewHTTPError(http.StatusUnprocessableEntity, errors.UserIdRequired.Error()).SetInternal(errors.UserIdRequired)
+		return errors.UserIdRequired
 	}
 	uReq := new(models.UserRequest)
 	if err := c.Bind(uReq); err != nil {
-		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error()).SetInternal(err)
+		return err
 	}
 	user := models.User{
-		Id: userId,
-		Name: uReq.Name,
+		Id:    userId,
+		Name:  uReq.Name,
 		Email: uReq.Email,
 	}
 	err := s.Sis().Handlers().UpdateUser(c.Request().Context(), &user)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, &models.UserResponse{
@@ -57,14 +57,14 @@ func (s *ServiceImpl) handleUpdateUser(c echo.Context) error {
 func (s *ServiceImpl) handleGetUserById(c echo.Context) error {
 	userId := c.Param("userId")
 	if userId == "" {
-		return echo.NewHTTPError(http.StatusUnprocessableEntity, errors.UserIdRequired.Error()).SetInternal(errors.UserIdRequired)
+		return errors.UserIdRequired
 	}
 	user := models.User{
 		Id: userId,
 	}
 	err := s.Sis().Handlers().GetUser(c.Request().Context(), &user)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, &models.UserResponse{
@@ -78,7 +78,7 @@ func (s *ServiceImpl) handleGetUsers(c echo.Context) error {
 	var users []models.User
 	err := s.Sis().Handlers().GetUsers(c.Request().Context(), &users)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		return err
 	}
 
 	uResponses := make([]*models.UserResponse, 0)
@@ -95,7 +95,7 @@ func (s *ServiceImpl) handleGetUsers(c echo.Context) error {
 func (s *ServiceImpl) handleDeleteUser(c echo.Context) error {
 	userId := c.Param("userId")
 	if userId == "" {
-		return echo.NewHTTPError(http.StatusUnprocessableEntity, errors.UserIdRequired.Error()).SetInternal(errors.UserIdRequired)
+		return errors.UserIdRequired
 	}
 	user := models.User{
 		Id: userId,
@@ -103,7 +103,7 @@ func (s *ServiceImpl) handleDeleteUser(c echo.Context) error {
 
 	err := s.Sis().Handlers().DeleteUser(c.Request().Context(), &user)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, &models.UserResponse{
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/validator"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/labstack/echo/v4"
+	"reflect"
+)
+
+type (
+	echoValidator struct {
+		validator services.Validator
+	}
+	validatingBinder struct {
+		echo.DefaultBinder
+		validator services.Validator
+	}
+)
+
+func (v *echoValidator) Validate(i interface{}) error {
+	return validationError(v.validator.Validate(context.Background(), i))
+}
+
+func (b *validatingBinder) Bind(i interface{}, c echo.Context) error {
+	if err := b.DefaultBinder.Bind(i, c); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	switch reflect.Indirect(reflect.ValueOf(i)).Kind() {
+	case reflect.Struct:
+		return validationError(b.validator.Validate(ctx, i))
+	case reflect.Slice:
+		return validationError(b.validator.ValidateSlice(ctx, reflect.Indirect(reflect.ValueOf(i)).Interface()))
+	default:
+		return nil
+	}
+}
+
+func validationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	fields, ok := validator.FieldErrors(err)
+	if !ok {
+		return err
+	}
+	return errors.ValidationFailed.WithDetails(map[string]interface{}{"fields": fields}).Wrap(err)
+}
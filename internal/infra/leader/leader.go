@@ -0,0 +1,113 @@
+package leader
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"time"
+)
+
+const (
+	defaultTTL           = 15 * time.Second
+	defaultRetryInterval = 5 * time.Second
+)
+
+type (
+	// Runner is the job run by a single replica at a time.
+	Runner interface {
+		Run(ctx context.Context) error
+	}
+	RunnerFunc func(ctx context.Context) error
+
+	// Elector keeps a Runner running while it holds a lock on the Cache.
+	Elector struct {
+		sis           services.Sis
+		key           string
+		runner        Runner
+		ttl           time.Duration
+		retryInterval time.Duration
+	}
+)
+
+func (f RunnerFunc) Run(ctx context.Context) error {
+	return f(ctx)
+}
+
+func New(sis services.Sis, key string, runner Runner) *Elector {
+	return &Elector{
+		sis:           sis,
+		key:           key,
+		runner:        runner,
+		ttl:           defaultTTL,
+		retryInterval: defaultRetryInterval,
+	}
+}
+
+// WithTTL sets the leadership lease.
+func (e *Elector) WithTTL(ttl time.Duration) *Elector {
+	e.ttl = ttl
+	return e
+}
+
+func (e *Elector) WithRetryInterval(interval time.Duration) *Elector {
+	e.retryInterval = interval
+	return e
+}
+
+// Run campaigns until ctx is done or the Runner returns by itself.
+func (e *Elector) Run(ctx context.Context) error {
+	for {
+		lock, err := e.sis.Cache().Lock(ctx, e.key, e.ttl)
+		switch {
+		case err == nil:
+			lost, err := e.lead(ctx, lock)
+			if !lost {
+				return err
+			}
+			e.sis.Logger().Warn(ctx, fmt.Sprintf("Leadership of %v lost", e.key))
+		case !goerrors.Is(err, errors.CacheLockNotAcquired):
+			e.sis.Logger().Error(ctx, fmt.Sprintf("Leader election of %v failed: %v", e.key, err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(e.retryInterval):
+		}
+	}
+}
+
+func (e *Elector) lead(ctx context.Context, lock services.Lock) (lost bool, err error) {
+	e.sis.Logger().Info(ctx, fmt.Sprintf("Leadership of %v acquired", e.key), map[string]interface{}{
+		"fencing_token": lock.FencingToken(),
+	})
+	leaderCtx, cancel := context.WithCancel(ctxs.ContextWithFencingToken(ctx, lock.FencingToken()))
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- e.runner.Run(leaderCtx)
+	}()
+
+	select {
+	case err = <-result:
+	case <-lock.Lost():
+		cancel()
+		<-result
+		return true, nil
+	case <-ctx.Done():
+		cancel()
+		err = <-result
+	}
+
+	releaseCtx, releaseCancel := context.WithTimeout(context.Background(), e.ttl)
+	defer releaseCancel()
+	if errR := lock.Release(releaseCtx); errR != nil {
+		e.sis.Logger().Warn(ctx, fmt.Sprintf("Leadership of %v not released: %v", e.key, errR))
+	}
+	e.sis.Logger().Info(ctx, fmt.Sprintf("Leadership of %v released", e.key))
+	return false, err
+}
@@ -0,0 +1,50 @@
+package leader
+
+import (
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/infra/cache"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestElector_Run(t *testing.T) {
+	sm := services.New().WithCache(cache.NewLRU())
+	if err := sm.Init(); err != nil {
+		t.Error(err)
+	}
+
+	var running, maxRunning int32
+	runner := RunnerFunc(func(ctx context.Context) error {
+		if ctxs.GetFencingTokenFromContext(ctx) == 0 {
+			t.Error("expected fencing token on context")
+		}
+		if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&maxRunning) {
+			atomic.StoreInt32(&maxRunning, n)
+		}
+		<-ctx.Done()
+		atomic.AddInt32(&running, -1)
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_ = New(sm, "job", runner).WithTTL(time.Second).WithRetryInterval(10 * time.Millisecond).Run(ctx)
+			done <- struct{}{}
+		}()
+	}
+	<-done
+	<-done
+
+	if maxRunning != 1 {
+		t.Errorf("runner executed %d times concurrently, want 1", maxRunning)
+	}
+	if err := sm.Close(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	FormatJSON    = "json"
+	FormatLogfmt  = "logfmt"
+	FormatConsole = "console"
+
+	timestampFormat = "2006-01-02 15:04:05.000"
+)
+
+// NewFormatter returns the formatter named FormatJSON, FormatLogfmt or FormatConsole.
+func NewFormatter(name string) (logrus.Formatter, error) {
+	switch name {
+	case FormatJSON, "":
+		return &logrus.JSONFormatter{
+			TimestampFormat: timestampFormat,
+			FieldMap: logrus.FieldMap{
+				logrus.FieldKeyTime: "time",
+				logrus.FieldKeyMsg:  "text",
+			},
+		}, nil
+	case FormatLogfmt:
+		return &logrus.TextFormatter{
+			DisableColors:   true,
+			FullTimestamp:   true,
+			TimestampFormat: timestampFormat,
+			FieldMap: logrus.FieldMap{
+				logrus.FieldKeyTime: "time",
+				logrus.FieldKeyMsg:  "text",
+			},
+		}, nil
+	case FormatConsole:
+		return &logrus.TextFormatter{
+			ForceColors:     true,
+			FullTimestamp:   true,
+			TimestampFormat: timestampFormat,
+		}, nil
+	default:
+		return nil, errors.LogFormatUnsupported
+	}
+}
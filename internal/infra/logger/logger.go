@@ -3,24 +3,53 @@ package logger
 import (
 	"context"
 	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
 	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/openzipkin/zipkin-go"
 	"github.com/sirupsen/logrus"
-	"os"
+	"io/ioutil"
 	"runtime"
+	"strings"
+	"sync"
+	"time"
 )
 
+// sinkOff as the stdout level disables it, an empty one gets the default.
+const sinkOff = "off"
+
 type (
 	DefaultFields func(ctx context.Context, fields *map[string]interface{})
 	ServiceImpl   struct {
+		services.NoopHealth
 		serviceManager services.Sis
 		ctx            context.Context
 		logger         *logrus.Logger
 		defaultFields  DefaultFields
+		levelsMu       sync.RWMutex
+		level          logrus.Level
+		packageLevels  map[string]logrus.Level
+		serviceFields  map[string]interface{}
+		sinks          []*Sink
+		redactor       *Redactor
+		sampler        *Sampler
+	}
+	// childLogger adds its fields to the entries of its root ServiceImpl.
+	childLogger struct {
+		*ServiceImpl
+		fields map[string]interface{}
+		skip   int
 	}
 )
 
 func New() *ServiceImpl {
-	return &ServiceImpl{}
+	formatter, _ := NewFormatter(FormatJSON)
+	return &ServiceImpl{
+		// replaced by Init, it reports a failed Init to stdout
+		logger:        newLogrus([]*Sink{NewStdoutSink(logrus.TraceLevel, formatter)}),
+		level:         logrus.InfoLevel,
+		packageLevels: map[string]logrus.Level{},
+	}
 }
 
 func (s *ServiceImpl) WithDefaultFields(f DefaultFields) *ServiceImpl {
@@ -28,22 +57,81 @@ func (s *ServiceImpl) WithDefaultFields(f DefaultFields) *ServiceImpl {
 	return s
 }
 
+// WithSink adds a sink, replacing the ones built from the Environment.
+func (s *ServiceImpl) WithSink(sink *Sink) *ServiceImpl {
+	s.sinks = append(s.sinks, sink)
+	return s
+}
+
+// WithRedactor replaces the Redactor built from the Environment.
+func (s *ServiceImpl) WithRedactor(r *Redactor) *ServiceImpl {
+	s.redactor = r
+	return s
+}
+
+// WithSampler replaces the Sampler built from the Environment.
+func (s *ServiceImpl) WithSampler(sampler *Sampler) *ServiceImpl {
+	s.sampler = sampler
+	return s
+}
+
 func (s *ServiceImpl) Init(ctx context.Context) error {
 	s.ctx = ctx
-	s.logger = logrus.New()
-	s.logger.SetFormatter(&logrus.JSONFormatter{
-		TimestampFormat: "2006-01-02 15:04:05.000",
-		FieldMap: logrus.FieldMap{
-			logrus.FieldKeyTime: "time",
-			logrus.FieldKeyMsg:  "text",
-		},
-	})
-	s.logger.SetOutput(os.Stdout)
+	env := s.Sis().Environment()
+	if s.redactor == nil && env.LogRedactEnabled() {
+		r, err := NewRedactor(env.LogRedactKeys(), env.LogRedactPatterns())
+		if err != nil {
+			return err
+		}
+		s.redactor = r
+	}
+	if s.sampler == nil && env.LogSamplingEnabled() {
+		s.sampler = NewSampler(env.LogSamplingFirst(), env.LogSamplingThereafter(), env.LogSamplingInterval())
+	}
+	if len(s.sinks) == 0 {
+		sinks, err := sinksFromEnvironment(env)
+		if err != nil {
+			return err
+		}
+		s.sinks = sinks
+	}
+	if len(s.sinks) == 0 {
+		formatter, _ := NewFormatter(FormatJSON)
+		s.sinks = append(s.sinks, NewStdoutSink(logrus.TraceLevel, formatter))
+	}
+
+	s.logger = newLogrus(s.sinks)
+
+	s.serviceFields = map[string]interface{}{
+		"service":     env.Service(),
+		"version":     env.Version(),
+		"environment": env.Environment(),
+	}
+	if level := env.LogLevel(); level != "" {
+		if err := s.SetLevel(level); err != nil {
+			return err
+		}
+	}
+	for _, packageLevel := range env.LogPackageLevels() {
+		parts := strings.SplitN(packageLevel, "=", 2)
+		if len(parts) != 2 {
+			return errors.LogPackageLevelInvalid
+		}
+		if err := s.SetPackageLevel(parts[0], parts[1]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (s *ServiceImpl) Close() error {
-	return nil
+	var err error
+	for _, sink := range s.sinks {
+		if errC := sink.Close(); errC != nil {
+			err = errC
+		}
+	}
+	return err
 }
 
 func (s *ServiceImpl) WithSis(c services.Sis) services.Logger {
@@ -55,9 +143,115 @@ func (s *ServiceImpl) Sis() services.Sis {
 	return s.serviceManager
 }
 
-func (s *ServiceImpl) output(ctx context.Context, fields ...map[string]interface{}) *logrus.Entry {
+func (s *ServiceImpl) Level() string {
+	s.levelsMu.RLock()
+	defer s.levelsMu.RUnlock()
+	return s.level.String()
+}
+
+func (s *ServiceImpl) PackageLevels() map[string]string {
+	s.levelsMu.RLock()
+	defer s.levelsMu.RUnlock()
+	levels := make(map[string]string, len(s.packageLevels))
+	for pkg, level := range s.packageLevels {
+		levels[pkg] = level.String()
+	}
+	return levels
+}
+
+func (s *ServiceImpl) SetLevel(level string) error {
+	l, err := logrus.ParseLevel(level)
+	if err != nil {
+		return errors.LogLevelInvalid
+	}
+	s.levelsMu.Lock()
+	defer s.levelsMu.Unlock()
+	s.level = l
+	return nil
+}
+
+// SetPackageLevel overrides the level of pkg and its sub packages, an empty level removes the override.
+func (s *ServiceImpl) SetPackageLevel(pkg string, level string) error {
+	if pkg == "" {
+		return errors.LogPackageRequired
+	}
+	if level == "" {
+		s.levelsMu.Lock()
+		defer s.levelsMu.Unlock()
+		delete(s.packageLevels, pkg)
+		return nil
+	}
+	l, err := logrus.ParseLevel(level)
+	if err != nil {
+		return errors.LogLevelInvalid
+	}
+	s.levelsMu.Lock()
+	defer s.levelsMu.Unlock()
+	s.packageLevels[pkg] = l
+	return nil
+}
+
+// Dropped returns the number of entries dropped by sampling by level and message, as "level:message".
+func (s *ServiceImpl) Dropped() map[string]uint64 {
+	if s.sampler == nil {
+		return map[string]uint64{}
+	}
+	dropped := s.sampler.Dropped()
+	if s.redactor == nil {
+		return dropped
+	}
+	redacted := make(map[string]uint64, len(dropped))
+	for k, v := range dropped {
+		redacted[s.redactor.Message(k)] += v
+	}
+	return redacted
+}
+
+// enabled resolves the level of pkg by the longest package override that prefixes it.
+func (s *ServiceImpl) enabled(level logrus.Level, pkg string) bool {
+	s.levelsMu.RLock()
+	defer s.levelsMu.RUnlock()
+	threshold := s.level
+	matched := -1
+	for p, l := range s.packageLevels {
+		if len(p) > matched && (pkg == p || strings.HasPrefix(pkg, p+"/")) {
+			threshold = l
+			matched = len(p)
+		}
+	}
+	return level <= threshold
+}
+
+// With returns a child Logger that adds fields to each of its entries.
+func (s *ServiceImpl) With(fields map[string]interface{}) services.Logger {
+	return &childLogger{ServiceImpl: s, fields: mergeFields(nil, fields)}
+}
+
+// WithCallerSkip returns a child Logger attributing its entries to the caller skip frames further up.
+func (s *ServiceImpl) WithCallerSkip(skip int) services.Logger {
+	return &childLogger{ServiceImpl: s, skip: skip}
+}
+
+func (s *ServiceImpl) log(ctx context.Context, skip int, level logrus.Level, message string, fields ...map[string]interface{}) {
+	funcName := ""
+	line := 0
+	if pc, _, l, ok := runtime.Caller(2 + skip); ok {
+		funcName = runtime.FuncForPC(pc).Name()
+		line = l
+	}
+	if level > logrus.FatalLevel && !s.enabled(level, packageOf(funcName)) {
+		return
+	}
+	if s.sampler != nil && !s.sampler.allow(level, message, time.Now()) {
+		return
+	}
+
 	f := make(map[string]interface{}, 0)
 
+	contextFields(ctx, f)
+	for k, v := range s.serviceFields {
+		f[k] = v
+	}
 	if s.defaultFields != nil {
 		s.defaultFields(ctx, &f)
 	}
@@ -68,28 +262,178 @@ func (s *ServiceImpl) output(ctx context.Context, fields ...map[string]interface
 		}
 	}
 
-	stack := ""
-	pc, _, line, ok := runtime.Caller(2)
-	if ok {
-		stack = fmt.Sprintf("%s:%d", runtime.FuncForPC(pc).Name(), line)
+	if s.redactor != nil {
+		message = s.redactor.Message(message)
+		s.redactor.Fields(f)
+	}
+	f["caller"] = fmt.Sprintf("%s:%d", funcName, line)
+
+	s.logger.WithFields(f).Log(level, message)
+	if level == logrus.FatalLevel {
+		s.logger.Exit(1)
+	}
+}
+
+func newLogrus(sinks []*Sink) *logrus.Logger {
+	logger := logrus.New()
+	// entries are written by the sinks, each one with its own formatter
+	logger.SetOutput(ioutil.Discard)
+	for _, sink := range sinks {
+		logger.AddHook(sink)
+	}
+	// levels are filtered by enabled, logrus must let everything through
+	logger.SetLevel(logrus.TraceLevel)
+	return logger
+}
+
+func sinksFromEnvironment(env services.Environment) ([]*Sink, error) {
+	sinks := make([]*Sink, 0)
+	if level := env.LogStdoutLevel(); level != "" && level != sinkOff {
+		level, formatter, err := sinkSettings(env.LogStdoutLevel(), env.LogStdoutFormat())
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, NewStdoutSink(level, formatter))
+	}
+	if env.LogFilePath() != "" {
+		level, formatter, err := sinkSettings(env.LogFileLevel(), env.LogFileFormat())
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, NewFileSink(FileSinkConfig{
+			Path:           env.LogFilePath(),
+			MaxSizeMB:      env.LogFileMaxSizeMB(),
+			MaxAgeDays:     env.LogFileMaxAgeDays(),
+			MaxBackups:     env.LogFileMaxBackups(),
+			Compress:       env.LogFileCompress(),
+			RotateInterval: env.LogFileRotateInterval(),
+		}, level, formatter))
+	}
+	if env.LogSyslogAddress() != "" {
+		level, formatter, err := sinkSettings(env.LogSyslogLevel(), env.LogSyslogFormat())
+		if err != nil {
+			return nil, err
+		}
+		sink, err := NewSyslogSink(env.LogSyslogAddress(), env.Service(), level, formatter)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+func sinkSettings(level string, format string) (logrus.Level, logrus.Formatter, error) {
+	l, err := logrus.ParseLevel(level)
+	if err != nil {
+		return 0, nil, errors.LogLevelInvalid
+	}
+	formatter, err := NewFormatter(format)
+	if err != nil {
+		return 0, nil, err
+	}
+	return l, formatter, nil
+}
+
+// contextFields adds the correlation id and the ids of the active span.
+func contextFields(ctx context.Context, f map[string]interface{}) {
+	if ctx == nil {
+		return
+	}
+	if cid := ctxs.GetCidFromContext(ctx); cid != nil {
+		f["cid"] = *cid
+	}
+	if span := zipkin.SpanFromContext(ctx); span != nil {
+		sc := span.Context()
+		f["trace_id"] = sc.TraceID.String()
+		f["span_id"] = sc.ID.String()
+	}
+}
+
+func mergeFields(fields ...map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for _, ff := range fields {
+		for k, v := range ff {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// packageOf extracts the import path from a function name as github.com/org/repo/pkg.(*Type).Method.
+func packageOf(funcName string) string {
+	lastSlash := strings.LastIndexByte(funcName, '/')
+	if dot := strings.IndexByte(funcName[lastSlash+1:], '.'); dot >= 0 {
+		return funcName[:lastSlash+1+dot]
 	}
-	f["caller"] = stack
+	return funcName
+}
 
-	return s.logger.WithFields(f)
+func (s *ServiceImpl) Trace(ctx context.Context, message string, fields ...map[string]interface{}) {
+	s.log(ctx, 0, logrus.TraceLevel, message, fields...)
+}
+
+func (s *ServiceImpl) Debug(ctx context.Context, message string, fields ...map[string]interface{}) {
+	s.log(ctx, 0, logrus.DebugLevel, message, fields...)
 }
 
 func (s *ServiceImpl) Info(ctx context.Context, message string, fields ...map[string]interface{}) {
-	s.output(ctx, fields...).Info(message)
+	s.log(ctx, 0, logrus.InfoLevel, message, fields...)
 }
 
 func (s *ServiceImpl) Warn(ctx context.Context, message string, fields ...map[string]interface{}) {
-	s.output(ctx, fields...).Warn(message)
+	s.log(ctx, 0, logrus.WarnLevel, message, fields...)
 }
 
 func (s *ServiceImpl) Error(ctx context.Context, message string, fields ...map[string]interface{}) {
-	s.output(ctx, fields...).Error(message)
+	s.log(ctx, 0, logrus.ErrorLevel, message, fields...)
 }
 
 func (s *ServiceImpl) Fatal(ctx context.Context, message string, fields ...map[string]interface{}) {
-	s.output(ctx, fields...).Fatal(message)
+	s.log(ctx, 0, logrus.FatalLevel, message, fields...)
+}
+
+func (c *childLogger) Init(_ context.Context) error {
+	return nil
+}
+
+// Close does nothing, the sinks belong to the root logger.
+func (c *childLogger) Close() error {
+	return nil
+}
+
+func (c *childLogger) WithSis(_ services.Sis) services.Logger {
+	return c
+}
+
+func (c *childLogger) With(fields map[string]interface{}) services.Logger {
+	return &childLogger{ServiceImpl: c.ServiceImpl, fields: mergeFields(c.fields, fields), skip: c.skip}
+}
+
+func (c *childLogger) WithCallerSkip(skip int) services.Logger {
+	return &childLogger{ServiceImpl: c.ServiceImpl, fields: c.fields, skip: c.skip + skip}
+}
+
+func (c *childLogger) Trace(ctx context.Context, message string, fields ...map[string]interface{}) {
+	c.log(ctx, c.skip, logrus.TraceLevel, message, append([]map[string]interface{}{c.fields}, fields...)...)
+}
+
+func (c *childLogger) Debug(ctx context.Context, message string, fields ...map[string]interface{}) {
+	c.log(ctx, c.skip, logrus.DebugLevel, message, append([]map[string]interface{}{c.fields}, fields...)...)
+}
+
+func (c *childLogger) Info(ctx context.Context, message string, fields ...map[string]interface{}) {
+	c.log(ctx, c.skip, logrus.InfoLevel, message, append([]map[string]interface{}{c.fields}, fields...)...)
+}
+
+func (c *childLogger) Warn(ctx context.Context, message string, fields ...map[string]interface{}) {
+	c.log(ctx, c.skip, logrus.WarnLevel, message, append([]map[string]interface{}{c.fields}, fields...)...)
+}
+
+func (c *childLogger) Error(ctx context.Context, message string, fields ...map[string]interface{}) {
+	c.log(ctx, c.skip, logrus.ErrorLevel, message, append([]map[string]interface{}{c.fields}, fields...)...)
+}
+
+func (c *childLogger) Fatal(ctx context.Context, message string, fields ...map[string]interface{}) {
+	c.log(ctx, c.skip, logrus.FatalLevel, message, append([]map[string]interface{}{c.fields}, fields...)...)
 }
@@ -1 +1,336 @@
 package logger
+
+import (
+	"bytes"
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/openzipkin/zipkin-go"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServiceImpl_enabled(t *testing.T) {
+	serviceImpl := New()
+	if err := serviceImpl.SetLevel("warn"); err != nil {
+		t.Error(err)
+	}
+	if err := serviceImpl.SetPackageLevel("github.com/dalmarcogd/bpl-go/internal/infra", "debug"); err != nil {
+		t.Error(err)
+	}
+	if err := serviceImpl.SetPackageLevel("github.com/dalmarcogd/bpl-go/internal/infra/http", "error"); err != nil {
+		t.Error(err)
+	}
+	if err := serviceImpl.SetLevel("verbose"); err == nil {
+		t.Error("expected invalid level error")
+	}
+
+	tests := []struct {
+		name  string
+		level logrus.Level
+		pkg   string
+		want  bool
+	}{
+		{name: "global-info", level: logrus.InfoLevel, pkg: "github.com/dalmarcogd/bpl-go/internal/handlers", want: false},
+		{name: "global-warn", level: logrus.WarnLevel, pkg: "github.com/dalmarcogd/bpl-go/internal/handlers", want: true},
+		{name: "package-debug", level: logrus.DebugLevel, pkg: "github.com/dalmarcogd/bpl-go/internal/infra/cache", want: true},
+		{name: "longest-package", level: logrus.WarnLevel, pkg: "github.com/dalmarcogd/bpl-go/internal/infra/http", want: false},
+		{name: "not-a-sub-package", level: logrus.DebugLevel, pkg: "github.com/dalmarcogd/bpl-go/internal/infrastructure", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serviceImpl.enabled(tt.level, tt.pkg); got != tt.want {
+				t.Errorf("enabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackageOf(t *testing.T) {
+	tests := map[string]string{
+		"github.com/dalmarcogd/bpl-go/internal/infra/http.(*ServiceImpl).handleGetUsers": "github.com/dalmarcogd/bpl-go/internal/infra/http",
+		"github.com/dalmarcogd/bpl-go/internal/services.(*sisImpl).Init":                 "github.com/dalmarcogd/bpl-go/internal/services",
+		"main.main": "main",
+	}
+	for funcName, want := range tests {
+		if got := packageOf(funcName); got != want {
+			t.Errorf("packageOf(%v) = %v, want %v", funcName, got, want)
+		}
+	}
+}
+
+func TestContextFields(t *testing.T) {
+	tracer, err := zipkin.NewTracer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	span, ctx := tracer.StartSpanFromContext(ctxs.ContextWithCid(context.Background(), "mycid"), "test")
+	defer span.Finish()
+
+	f := map[string]interface{}{}
+	contextFields(ctx, f)
+	if f["cid"] != "mycid" {
+		t.Errorf("cid = %v, want mycid", f["cid"])
+	}
+	if f["trace_id"] != span.Context().TraceID.String() || f["span_id"] != span.Context().ID.String() {
+		t.Errorf("trace_id, span_id = %v, %v, want ids of the active span", f["trace_id"], f["span_id"])
+	}
+}
+
+func TestServiceImpl_Sinks(t *testing.T) {
+	jsonFormatter, _ := NewFormatter(FormatJSON)
+	logfmtFormatter, _ := NewFormatter(FormatLogfmt)
+	all, errorsOnly := new(bytes.Buffer), new(bytes.Buffer)
+	serviceImpl := New().
+		WithSink(NewSink("all", all, logrus.TraceLevel, jsonFormatter)).
+		WithSink(NewSink("errors", errorsOnly, logrus.ErrorLevel, logfmtFormatter))
+	sm := services.New().WithLogger(serviceImpl)
+	if err := sm.Init(); err != nil {
+		t.Error(err)
+	}
+	all.Reset()
+
+	ctx := context.Background()
+	serviceImpl.Info(ctx, "first")
+	serviceImpl.Error(ctx, "second")
+
+	if !strings.Contains(all.String(), `"text":"first"`) || !strings.Contains(all.String(), `"text":"second"`) {
+		t.Errorf("all sink = %v, want both entries as json", all.String())
+	}
+	if strings.Contains(errorsOnly.String(), "first") || !strings.Contains(errorsOnly.String(), "text=second") {
+		t.Errorf("errors sink = %v, want only the error entry as logfmt", errorsOnly.String())
+	}
+	if err := sm.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+// adapterInfo stands for an adapter of the logger of another library.
+func adapterInfo(log services.Logger, message string) {
+	log.Info(context.Background(), message)
+}
+
+func TestServiceImpl_WithCallerSkip(t *testing.T) {
+	formatter, _ := NewFormatter(FormatJSON)
+	out := new(bytes.Buffer)
+	serviceImpl := New().WithSink(NewSink("buffer", out, logrus.TraceLevel, formatter))
+	sm := services.New().WithLogger(serviceImpl)
+	if err := sm.Init(); err != nil {
+		t.Error(err)
+	}
+	out.Reset()
+
+	adapterInfo(serviceImpl.With(map[string]interface{}{"component": "lib"}), "from the adapter")
+	if !strings.Contains(out.String(), `logger.adapterInfo`) {
+		t.Errorf("entry = %v, want the adapter as caller", out.String())
+	}
+	out.Reset()
+	adapterInfo(serviceImpl.With(map[string]interface{}{"component": "lib"}).WithCallerSkip(1), "from the library")
+	for _, want := range []string{`"component":"lib"`, `logger.TestServiceImpl_WithCallerSkip`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("entry = %v, want it to contain %v", out.String(), want)
+		}
+	}
+	if err := sm.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+type stdoutEnvironment struct {
+	services.NoopEnvironment
+	level string
+}
+
+func (e *stdoutEnvironment) LogStdoutLevel() string {
+	return e.level
+}
+
+func (e *stdoutEnvironment) LogStdoutFormat() string {
+	return FormatJSON
+}
+
+func TestSinksFromEnvironment(t *testing.T) {
+	tests := map[string]int{"trace": 1, "warn": 1, sinkOff: 0}
+	for level, want := range tests {
+		sinks, err := sinksFromEnvironment(&stdoutEnvironment{level: level})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sinks) != want {
+			t.Errorf("sinks of stdout level %v = %v, want %v", level, len(sinks), want)
+		}
+	}
+	if _, err := sinksFromEnvironment(&stdoutEnvironment{level: "verbose"}); err == nil {
+		t.Error("expected invalid level error")
+	}
+}
+
+type redactEnvironment struct {
+	services.NoopEnvironment
+}
+
+func (e *redactEnvironment) WithSis(_ services.Sis) services.Environment {
+	return e
+}
+
+func (e *redactEnvironment) LogRedactEnabled() bool {
+	return true
+}
+
+func (e *redactEnvironment) LogRedactPatterns() []string {
+	return []string{"phone"}
+}
+
+func TestServiceImpl_FatalAfterFailedInit(t *testing.T) {
+	stdout := os.Stdout
+	out, err := ioutil.TempFile(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = out
+	serviceImpl := New()
+	os.Stdout = stdout
+
+	sm := services.New().WithEnvironment(&redactEnvironment{}).WithLogger(serviceImpl)
+	if err := sm.Init(); err == nil {
+		t.Fatal("expected unsupported pattern error")
+	}
+	exitCode := 0
+	serviceImpl.logger.ExitFunc = func(code int) {
+		exitCode = code
+	}
+	serviceImpl.Fatal(context.Background(), "init failed")
+
+	written, _ := ioutil.ReadFile(out.Name())
+	if !strings.Contains(string(written), `"text":"init failed"`) || exitCode != 1 {
+		t.Errorf("stdout = %s exit = %d, want the fatal entry and exit 1", written, exitCode)
+	}
+}
+
+func TestServiceImpl_With(t *testing.T) {
+	formatter, _ := NewFormatter(FormatJSON)
+	out := new(bytes.Buffer)
+	serviceImpl := New().WithSink(NewSink("buffer", out, logrus.TraceLevel, formatter))
+	sm := services.New().WithLogger(serviceImpl)
+	if err := sm.Init(); err != nil {
+		t.Error(err)
+	}
+	out.Reset()
+
+	child := serviceImpl.With(map[string]interface{}{"route": "/v1/users"}).With(map[string]interface{}{"tenant": "t1"})
+	child.Info(context.Background(), "bound", map[string]interface{}{"user_id": "u1"})
+
+	for _, want := range []string{`"route":"/v1/users"`, `"tenant":"t1"`, `"user_id":"u1"`, `logger.TestServiceImpl_With`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("entry = %v, want it to contain %v", out.String(), want)
+		}
+	}
+	if err := child.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := sm.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+type redactedUser struct {
+	Name     *string `json:"name"`
+	Email    *string `json:"email"`
+	Password string  `json:"-"`
+	internal string
+}
+
+type quotedError string
+
+func (e quotedError) Error() string {
+	return string(e)
+}
+
+func TestRedactor(t *testing.T) {
+	r, err := NewRedactor([]string{"email", "password", "token"}, []string{RedactPatternEmail, RedactPatternCard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRedactor(nil, []string{"phone"}); err == nil {
+		t.Error("expected unsupported pattern error")
+	}
+
+	message := r.Message("user john@doe.com paid with 4111 1111 1111 1111, order 1234567890123")
+	if want := "user [REDACTED] paid with [REDACTED], order 1234567890123"; message != want {
+		t.Errorf("Message() = %v, want %v", message, want)
+	}
+
+	name, email := "John", "john@doe.com"
+	notFound := quotedError("not found")
+	fields := map[string]interface{}{
+		"Email":         "john@doe.com",
+		"Password":      "secret",
+		"fencing_token": int64(7),
+		"name":          &name,
+		"request":       map[string]interface{}{"email": "x@y.io", "note": "mail me at x@y.io"},
+		"user":          redactedUser{Name: &name, Email: &email, internal: "x"},
+		"owner":         &redactedUser{Name: &name, Email: &email},
+		"page":          &struct{ Size int }{Size: 10},
+		"error":         notFound,
+		"cause":         quotedError("no user x@y.io"),
+		"count":         3,
+	}
+	r.Fields(fields)
+	if fields["Email"] != redactedMask || fields["Password"] != redactedMask || fields["count"] != 3 {
+		t.Errorf("Fields() = %v", fields)
+	}
+	if fields["fencing_token"] != int64(7) {
+		t.Errorf("fencing_token = %v, want the keys matched whole", fields["fencing_token"])
+	}
+	if fields["name"] != &name || fields["error"] != notFound {
+		t.Errorf("name, error = %#v, %#v, want them unchanged", fields["name"], fields["error"])
+	}
+	if fields["cause"] != "no user [REDACTED]" {
+		t.Errorf("cause = %#v", fields["cause"])
+	}
+	nested := fields["request"].(map[string]interface{})
+	if nested["email"] != redactedMask || nested["note"] != "mail me at [REDACTED]" {
+		t.Errorf("Fields() nested = %v", nested)
+	}
+	user, ok := fields["user"].(map[string]interface{})
+	if !ok || user["email"] != redactedMask || user["name"] != &name || len(user) != 2 {
+		t.Errorf("Fields() struct = %#v, want its fields by json name", fields["user"])
+	}
+	if owner, ok := fields["owner"].(map[string]interface{}); !ok || owner["email"] != redactedMask {
+		t.Errorf("Fields() struct pointer = %#v, want its fields by json name", fields["owner"])
+	}
+	if page, ok := fields["page"].(*struct{ Size int }); !ok || page.Size != 10 {
+		t.Errorf("Fields() struct = %#v, want it unchanged", fields["page"])
+	}
+}
+
+func TestSampler(t *testing.T) {
+	sampler := NewSampler(2, 3, time.Second)
+	now := time.Now()
+
+	allowed := 0
+	for i := 0; i < 11; i++ {
+		if sampler.allow(logrus.InfoLevel, "Request GET:/v1/users", now) {
+			allowed++
+		}
+	}
+	// the first 2 and then the 3rd, 6th and 9th of the remaining 9
+	if allowed != 5 {
+		t.Errorf("allowed = %v, want 5", allowed)
+	}
+	for i := 0; i < 10; i++ {
+		if !sampler.allow(logrus.ErrorLevel, "Request GET:/v1/users", now) {
+			t.Error("errors must never be sampled")
+		}
+	}
+	if !sampler.allow(logrus.InfoLevel, "Request GET:/v1/users", now.Add(time.Second)) {
+		t.Error("a new interval must let the first entries through")
+	}
+	if got := sampler.Dropped()["info:Request GET:/v1/users"]; got != 6 {
+		t.Errorf("Dropped() = %v, want 6", got)
+	}
+}
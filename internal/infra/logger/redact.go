@@ -0,0 +1,207 @@
+package logger
+
+import (
+	"fmt"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const (
+	RedactPatternEmail = "email"
+	RedactPatternCard  = "card"
+
+	redactedMask = "[REDACTED]"
+)
+
+var (
+	redactPatterns = map[string]*regexp.Regexp{
+		RedactPatternEmail: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		RedactPatternCard:  regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+	}
+	// redactValidators discards the matches that are not sensitive, as long numbers that are not cards.
+	redactValidators = map[string]func(match string) bool{
+		RedactPatternCard: luhnValid,
+	}
+)
+
+type (
+	redactPattern struct {
+		regexp   *regexp.Regexp
+		validate func(match string) bool
+	}
+	// Redactor masks the values of sensitive keys and the sensitive patterns of messages and strings.
+	Redactor struct {
+		keys     []string
+		patterns []redactPattern
+	}
+)
+
+// NewRedactor builds a Redactor for keys and the built-in patterns named RedactPatternEmail or RedactPatternCard.
+func NewRedactor(keys []string, patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, key := range keys {
+		r.keys = append(r.keys, strings.ToLower(key))
+	}
+	for _, name := range patterns {
+		re, ok := redactPatterns[name]
+		if !ok {
+			return nil, errors.LogRedactPatternUnsupported
+		}
+		r.patterns = append(r.patterns, redactPattern{regexp: re, validate: redactValidators[name]})
+	}
+	return r, nil
+}
+
+// WithPattern adds a custom pattern.
+func (r *Redactor) WithPattern(re *regexp.Regexp) *Redactor {
+	r.patterns = append(r.patterns, redactPattern{regexp: re})
+	return r
+}
+
+func (r *Redactor) Message(message string) string {
+	for _, p := range r.patterns {
+		message = p.regexp.ReplaceAllStringFunc(message, func(match string) string {
+			if p.validate != nil && !p.validate(match) {
+				return match
+			}
+			return redactedMask
+		})
+	}
+	return message
+}
+
+// Fields masks fields in place.
+func (r *Redactor) Fields(fields map[string]interface{}) {
+	for k, v := range fields {
+		if r.sensitiveKey(k) {
+			fields[k] = redactedMask
+			continue
+		}
+		fields[k] = r.value(v)
+	}
+}
+
+// value keeps the type of v when it has nothing to mask.
+func (r *Redactor) value(v interface{}) interface{} {
+	switch value := v.(type) {
+	case string:
+		return r.Message(value)
+	case *string:
+		if value == nil {
+			return value
+		}
+		if message := r.Message(*value); message != *value {
+			return message
+		}
+		return v
+	case error:
+		if message := r.Message(value.Error()); message != value.Error() {
+			return message
+		}
+		return v
+	case fmt.Stringer:
+		if message := r.Message(value.String()); message != value.String() {
+			return message
+		}
+		return v
+	case map[string]interface{}:
+		nested := make(map[string]interface{}, len(value))
+		for k, nv := range value {
+			nested[k] = nv
+		}
+		r.Fields(nested)
+		return nested
+	default:
+		return r.structValue(v)
+	}
+}
+
+// structValue is the map of the fields of v by json name when any of them is masked.
+func (r *Redactor) structValue(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return v
+	}
+	fields := make(map[string]interface{})
+	jsonFields(rv, fields)
+	redacted := make(map[string]interface{}, len(fields))
+	for k, fv := range fields {
+		redacted[k] = fv
+	}
+	r.Fields(redacted)
+	if reflect.DeepEqual(fields, redacted) {
+		return v
+	}
+	return redacted
+}
+
+func jsonFields(rv reflect.Value, fields map[string]interface{}) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field, value := rt.Field(i), rv.Field(i)
+		name, opts := field.Name, ""
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			if comma := strings.Index(tag, ","); comma >= 0 {
+				tag, opts = tag[:comma], tag[comma:]
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		if field.Anonymous && name == field.Name {
+			for value.Kind() == reflect.Ptr && !value.IsNil() {
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				jsonFields(value, fields)
+				continue
+			}
+		}
+		if !value.CanInterface() {
+			continue
+		}
+		if strings.Contains(opts, "omitempty") && value.IsZero() {
+			continue
+		}
+		fields[name] = value.Interface()
+	}
+}
+
+func (r *Redactor) sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range r.keys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// luhnValid reports whether number passes the Luhn checksum of the card numbers.
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+const (
+	// samplerMaxKeys bounds the tracked messages, which may be built with variable data.
+	samplerMaxKeys = 10000
+	// samplerOtherKey counts the dropped entries of messages beyond samplerMaxKeys.
+	samplerOtherKey = "other"
+)
+
+type (
+	sampleCounter struct {
+		resetAt time.Time
+		count   int
+	}
+	// Sampler lets through the first entries of each message and level per interval, then one in thereafter.
+	Sampler struct {
+		mu         sync.Mutex
+		first      int
+		thereafter int
+		interval   time.Duration
+		counters   map[string]*sampleCounter
+		dropped    map[string]uint64
+	}
+)
+
+// NewSampler builds a Sampler, a thereafter lower than 1 drops every entry after the first ones.
+func NewSampler(first int, thereafter int, interval time.Duration) *Sampler {
+	return &Sampler{
+		first:      first,
+		thereafter: thereafter,
+		interval:   interval,
+		counters:   map[string]*sampleCounter{},
+		dropped:    map[string]uint64{},
+	}
+}
+
+// Dropped returns the number of entries dropped by level and message, as "level:message".
+func (s *Sampler) Dropped() map[string]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dropped := make(map[string]uint64, len(s.dropped))
+	for k, v := range s.dropped {
+		dropped[k] = v
+	}
+	return dropped
+}
+
+func (s *Sampler) allow(level logrus.Level, message string, now time.Time) bool {
+	if level <= logrus.ErrorLevel {
+		return true
+	}
+	key := level.String() + ":" + message
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counter, ok := s.counters[key]
+	if !ok {
+		if len(s.counters) >= samplerMaxKeys {
+			s.sweep(now)
+		}
+		counter = &sampleCounter{}
+		s.counters[key] = counter
+	}
+	if !now.Before(counter.resetAt) {
+		counter.resetAt = now.Add(s.interval)
+		counter.count = 0
+	}
+	counter.count++
+
+	if counter.count <= s.first {
+		return true
+	}
+	if s.thereafter > 0 && (counter.count-s.first)%s.thereafter == 0 {
+		return true
+	}
+	if _, ok := s.dropped[key]; !ok && len(s.dropped) >= samplerMaxKeys {
+		key = samplerOtherKey
+	}
+	s.dropped[key]++
+	return false
+}
+
+func (s *Sampler) sweep(now time.Time) {
+	for key, counter := range s.counters {
+		if !now.Before(counter.resetAt) {
+			delete(s.counters, key)
+		}
+	}
+}
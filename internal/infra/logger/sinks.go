@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+type (
+	// Sink is a logrus hook writing the entries at or above its level with its own formatter.
+	Sink struct {
+		name      string
+		write     func(level logrus.Level, line []byte) error
+		formatter logrus.Formatter
+		level     logrus.Level
+		mu        sync.Mutex
+		close     func() error
+	}
+	// FileSinkConfig configures the rotation of a file sink.
+	FileSinkConfig struct {
+		Path           string
+		MaxSizeMB      int
+		MaxAgeDays     int
+		MaxBackups     int
+		Compress       bool
+		RotateInterval time.Duration
+	}
+)
+
+func NewSink(name string, writer io.Writer, level logrus.Level, formatter logrus.Formatter) *Sink {
+	return &Sink{
+		name: name,
+		write: func(_ logrus.Level, line []byte) error {
+			_, err := writer.Write(line)
+			return err
+		},
+		formatter: formatter,
+		level:     level,
+	}
+}
+
+func NewStdoutSink(level logrus.Level, formatter logrus.Formatter) *Sink {
+	s := NewSink("stdout", os.Stdout, level, formatter)
+	s.close = func() error {
+		// stdout is not buffered, syncing it fails when it is a pipe or a terminal
+		_ = os.Stdout.Sync()
+		return nil
+	}
+	return s
+}
+
+func NewFileSink(config FileSinkConfig, level logrus.Level, formatter logrus.Formatter) *Sink {
+	file := &lumberjack.Logger{
+		Filename:   config.Path,
+		MaxSize:    config.MaxSizeMB,
+		MaxAge:     config.MaxAgeDays,
+		MaxBackups: config.MaxBackups,
+		Compress:   config.Compress,
+		LocalTime:  false,
+	}
+	s := NewSink("file", file, level, formatter)
+
+	done := make(chan struct{})
+	if config.RotateInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(config.RotateInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					s.mu.Lock()
+					_ = file.Rotate()
+					s.mu.Unlock()
+				}
+			}
+		}()
+	}
+	s.close = func() error {
+		close(done)
+		return file.Close()
+	}
+	return s
+}
+
+func (s *Sink) Name() string {
+	return s.name
+}
+
+func (s *Sink) Levels() []logrus.Level {
+	levels := make([]logrus.Level, 0, len(logrus.AllLevels))
+	for _, level := range logrus.AllLevels {
+		if level <= s.level {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+func (s *Sink) Fire(entry *logrus.Entry) error {
+	line, err := s.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(entry.Level, line)
+}
+
+// Close flushes and releases the sink.
+func (s *Sink) Close() error {
+	if s.close == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.close()
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package logger
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/sirupsen/logrus"
+)
+
+func NewSyslogSink(_ string, _ string, _ logrus.Level, _ logrus.Formatter) (*Sink, error) {
+	return nil, errors.LogSyslogUnsupported
+}
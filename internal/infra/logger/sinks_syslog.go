@@ -0,0 +1,44 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package logger
+
+import (
+	"github.com/sirupsen/logrus"
+	"log/syslog"
+)
+
+type syslogWriter struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink writes to the local syslog listening on the unix socket address, as /dev/log.
+func NewSyslogSink(address string, tag string, level logrus.Level, formatter logrus.Formatter) (*Sink, error) {
+	w, err := syslog.Dial("unixgram", address, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		w, err = syslog.Dial("unix", address, syslog.LOG_INFO|syslog.LOG_USER, tag)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s := NewSink("syslog", nil, level, formatter)
+	s.write = (&syslogWriter{writer: w}).write
+	s.close = w.Close
+	return s, nil
+}
+
+func (w *syslogWriter) write(level logrus.Level, line []byte) error {
+	message := string(line)
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return w.writer.Crit(message)
+	case logrus.ErrorLevel:
+		return w.writer.Err(message)
+	case logrus.WarnLevel:
+		return w.writer.Warning(message)
+	case logrus.InfoLevel:
+		return w.writer.Info(message)
+	default:
+		return w.writer.Debug(message)
+	}
+}
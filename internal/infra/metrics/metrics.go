@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"context"
+	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+type (
+	// ServiceImpl keeps the metrics on its own prometheus registry, registering a name again returns its metric.
+	ServiceImpl struct {
+		services.NoopHealth
+		serviceManager services.Sis
+		ctx            context.Context
+		registry       *prometheus.Registry
+	}
+	counter struct {
+		vec *prometheus.CounterVec
+	}
+	gauge struct {
+		vec *prometheus.GaugeVec
+	}
+	histogram struct {
+		vec *prometheus.HistogramVec
+	}
+)
+
+func New() *ServiceImpl {
+	return &ServiceImpl{registry: prometheus.NewRegistry()}
+}
+
+func (s *ServiceImpl) Init(ctx context.Context) error {
+	s.ctx = ctx
+	s.register(prometheus.NewGoCollector())
+	s.register(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+	return nil
+}
+
+func (s *ServiceImpl) Close() error {
+	return nil
+}
+
+func (s *ServiceImpl) WithSis(c services.Sis) services.Metrics {
+	s.serviceManager = c
+	return s
+}
+
+func (s *ServiceImpl) Sis() services.Sis {
+	return s.serviceManager
+}
+
+func (s *ServiceImpl) Counter(name string, help string, labels ...string) services.Counter {
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels)
+	return &counter{vec: s.register(vec).(*prometheus.CounterVec)}
+}
+
+func (s *ServiceImpl) Gauge(name string, help string, labels ...string) services.Gauge {
+	vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labels)
+	return &gauge{vec: s.register(vec).(*prometheus.GaugeVec)}
+}
+
+// Histogram observes on buckets, the prometheus default buckets when nil.
+func (s *ServiceImpl) Histogram(name string, help string, buckets []float64, labels ...string) services.Histogram {
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: buckets}, labels)
+	return &histogram{vec: s.register(vec).(*prometheus.HistogramVec)}
+}
+
+// CounterFunc exposes the value of f on every scrape.
+func (s *ServiceImpl) CounterFunc(name string, help string, f func() float64) {
+	s.register(prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, f))
+}
+
+// GaugeFunc exposes the value of f on every scrape.
+func (s *ServiceImpl) GaugeFunc(name string, help string, f func() float64) {
+	s.register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, f))
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (s *ServiceImpl) Handler() http.Handler {
+	return promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{})
+}
+
+func (s *ServiceImpl) register(c prometheus.Collector) prometheus.Collector {
+	if err := s.registry.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector
+		}
+		panic(err)
+	}
+	return c
+}
+
+func (c *counter) Inc(labelValues ...string) {
+	c.vec.WithLabelValues(labelValues...).Inc()
+}
+
+func (c *counter) Add(v float64, labelValues ...string) {
+	c.vec.WithLabelValues(labelValues...).Add(v)
+}
+
+func (g *gauge) Set(v float64, labelValues ...string) {
+	g.vec.WithLabelValues(labelValues...).Set(v)
+}
+
+func (g *gauge) Add(v float64, labelValues ...string) {
+	g.vec.WithLabelValues(labelValues...).Add(v)
+}
+
+func (h *histogram) Observe(v float64, labelValues ...string) {
+	h.vec.WithLabelValues(labelValues...).Observe(v)
+}
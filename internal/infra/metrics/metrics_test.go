@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServiceImpl_Handler(t *testing.T) {
+	s := New()
+	if err := s.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	s.Counter("jobs_total", "Jobs run.", "status").Inc("ok")
+	// registered again the metric is shared
+	s.Counter("jobs_total", "Jobs run.", "status").Add(2, "ok")
+	s.Gauge("queue_size", "Jobs queued.").Set(7)
+	s.Histogram("job_duration_seconds", "Duration of the jobs.", []float64{1, 5}).Observe(3)
+	s.GaugeFunc("workers", "Workers running.", func() float64 { return 4 })
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`jobs_total{status="ok"} 3`,
+		`queue_size 7`,
+		`job_duration_seconds_bucket{le="5"} 1`,
+		`workers 4`,
+		`go_goroutines `,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics have no %q", want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package openapi
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+)
+
+const (
+	openAPIVersion      = "3.0.3"
+	mimeApplicationJSON = "application/json"
+	mimeProblemJSON     = "application/problem+json"
+	unknown             = "UNKNOWN"
+)
+
+var pathParam = regexp.MustCompile(`:([^/]+)`)
+
+type (
+	// Operation documents a route, a nil response has no body.
+	Operation struct {
+		Summary     string
+		Description string
+		Tags        []string
+		Roles       []string
+		Request     interface{}
+		Responses   map[int]interface{}
+	}
+	// Spec builds the OpenAPI document of the echo routes from their Operation.
+	Spec struct {
+		title      string
+		version    string
+		operations map[string]Operation
+		hidden     map[string]bool
+		security   map[string]*openapi3.SecurityScheme
+	}
+)
+
+// New builds a Spec for the document of title and version.
+func New(title string, version string) *Spec {
+	if title == "" {
+		title = unknown
+	}
+	if version == "" {
+		version = unknown
+	}
+	return &Spec{
+		title:      title,
+		version:    version,
+		operations: map[string]Operation{},
+		hidden:     map[string]bool{},
+		security:   map[string]*openapi3.SecurityScheme{},
+	}
+}
+
+// WithSecurityScheme adds a scheme authenticating the operations with roles.
+func (s *Spec) WithSecurityScheme(name string, scheme *openapi3.SecurityScheme) *Spec {
+	s.security[name] = scheme
+	return s
+}
+
+// Document describes route with op and returns route.
+func (s *Spec) Document(route *echo.Route, op Operation) *echo.Route {
+	s.operations[routeKey(route)] = op
+	return route
+}
+
+// Hide leaves route out of the document.
+func (s *Spec) Hide(route *echo.Route) *echo.Route {
+	s.hidden[routeKey(route)] = true
+	return route
+}
+
+// Undocumented returns the routes neither documented nor hidden.
+func (s *Spec) Undocumented(routes []*echo.Route) []*echo.Route {
+	undocumented := make([]*echo.Route, 0)
+	for _, route := range routes {
+		key := routeKey(route)
+		if _, ok := s.operations[key]; !ok && !s.hidden[key] && !IsGroupFallback(route) {
+			undocumented = append(undocumented, route)
+		}
+	}
+	return undocumented
+}
+
+// Build produces the OpenAPI document of the documented routes.
+func (s *Spec) Build(routes []*echo.Route) (*openapi3.Swagger, error) {
+	swagger := &openapi3.Swagger{
+		OpenAPI:    openAPIVersion,
+		Info:       &openapi3.Info{Title: s.title, Version: s.version},
+		Paths:      openapi3.Paths{},
+		Components: openapi3.NewComponents(),
+	}
+	swagger.Components.Schemas = map[string]*openapi3.SchemaRef{}
+	swagger.Components.SecuritySchemes = map[string]*openapi3.SecuritySchemeRef{}
+	security := openapi3.NewSecurityRequirements()
+	for _, name := range s.securitySchemeNames() {
+		swagger.Components.SecuritySchemes[name] = &openapi3.SecuritySchemeRef{Value: s.security[name]}
+		security.With(openapi3.NewSecurityRequirement().Authenticate(name))
+	}
+	schemas := newSchemaGenerator(swagger.Components.Schemas)
+	problem := schemas.ref(reflect.TypeOf(models.Problem{}))
+
+	sort.Slice(routes, func(i, j int) bool {
+		return routeKey(routes[i]) < routeKey(routes[j])
+	})
+	for _, route := range routes {
+		op, ok := s.operations[routeKey(route)]
+		if !ok {
+			continue
+		}
+		path := Path(route.Path)
+		item, ok := swagger.Paths[path]
+		if !ok {
+			item = &openapi3.PathItem{}
+			swagger.Paths[path] = item
+		}
+
+		operation := openapi3.NewOperation()
+		operation.Summary = op.Summary
+		operation.Description = op.Description
+		operation.Tags = op.Tags
+		if len(op.Roles) > 0 {
+			// the scopes of http schemes must be empty, the roles go on an extension
+			operation.Security = security
+			operation.Extensions = map[string]interface{}{"x-roles": op.Roles}
+		}
+		for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+			param := openapi3.NewPathParameter(match[1]).WithSchema(openapi3.NewStringSchema())
+			operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{Value: param})
+		}
+		if op.Request != nil {
+			operation.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().
+				WithRequired(true).
+				WithJSONSchemaRef(schemas.ref(reflect.TypeOf(op.Request)))}
+		}
+
+		operation.Responses = openapi3.Responses{}
+		for status, body := range op.Responses {
+			response := openapi3.NewResponse().WithDescription(http.StatusText(status))
+			if body != nil {
+				response.WithJSONSchemaRef(schemas.ref(reflect.TypeOf(body)))
+			}
+			operation.Responses[strconv.Itoa(status)] = &openapi3.ResponseRef{Value: response}
+		}
+		// every error is a problem
+		operation.Responses["default"] = &openapi3.ResponseRef{Value: openapi3.NewResponse().
+			WithDescription("Problem").
+			WithContent(openapi3.Content{mimeProblemJSON: openapi3.NewMediaType().WithSchemaRef(problem)})}
+
+		item.SetOperation(route.Method, operation)
+	}
+	return swagger, nil
+}
+
+func (s *Spec) securitySchemeNames() []string {
+	names := make([]string, 0, len(s.security))
+	for name := range s.security {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Path converts an echo path to an OpenAPI one, as /users/:userId to /users/{userId}.
+func Path(echoPath string) string {
+	return pathParam.ReplaceAllString(echoPath, "{$1}")
+}
+
+func routeKey(route *echo.Route) string {
+	return route.Method + " " + route.Path
+}
+
+// IsGroupFallback reports the routes added by echo to run the middlewares of a group on unknown paths.
+func IsGroupFallback(route *echo.Route) bool {
+	return route.Name == runtime.FuncForPC(reflect.ValueOf(echo.NotFoundHandler).Pointer()).Name()
+}
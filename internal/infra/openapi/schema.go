@@ -0,0 +1,179 @@
+package openapi
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func init() {
+	openapi3.DefineStringFormat("uuid", openapi3.FormatOfStringForUUIDOfRFC4122)
+}
+
+// schemaGenerator describes go types as schemas, named structs become components.
+type schemaGenerator struct {
+	components map[string]*openapi3.SchemaRef
+}
+
+func newSchemaGenerator(components map[string]*openapi3.SchemaRef) *schemaGenerator {
+	return &schemaGenerator{components: components}
+}
+
+func (g *schemaGenerator) ref(t reflect.Type) *openapi3.SchemaRef {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct && t.Name() != "" && t != timeType {
+		if _, ok := g.components[t.Name()]; !ok {
+			// registered before the fields so recursive types end
+			g.components[t.Name()] = &openapi3.SchemaRef{}
+			g.components[t.Name()].Value = g.schema(t)
+		}
+		return openapi3.NewSchemaRef("#/components/schemas/"+t.Name(), g.components[t.Name()].Value)
+	}
+	return g.schema(t).NewRef()
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) *openapi3.Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return openapi3.NewDateTimeSchema()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return openapi3.NewBoolSchema()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return openapi3.NewInt32Schema()
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return openapi3.NewInt64Schema()
+	case reflect.Float32, reflect.Float64:
+		return openapi3.NewFloat64Schema()
+	case reflect.String:
+		return openapi3.NewStringSchema()
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return openapi3.NewBytesSchema()
+		}
+		schema := openapi3.NewArraySchema()
+		schema.Items = g.ref(t.Elem())
+		return schema
+	case reflect.Map:
+		schema := openapi3.NewObjectSchema()
+		schema.AdditionalProperties = g.ref(t.Elem())
+		return schema
+	case reflect.Struct:
+		schema := openapi3.NewObjectSchema()
+		g.properties(schema, t)
+		return schema
+	default:
+		// interface{} accepts any value
+		return openapi3.NewSchema()
+	}
+}
+
+func (g *schemaGenerator) properties(schema *openapi3.Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+		// as in encoding/json the fields of an embedded struct are promoted, even when it is unexported
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.properties(schema, embedded)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.ref(field.Type)
+		if property.Ref == "" {
+			required := constrain(property.Value, field.Tag.Get("validate"))
+			if required {
+				schema.Required = append(schema.Required, name)
+			} else if field.Type.Kind() == reflect.Ptr {
+				property.Value.Nullable = true
+			}
+		}
+		schema.WithPropertyRef(name, property)
+	}
+}
+
+// constrain applies the validate rules to schema and reports whether the field is required.
+func constrain(schema *openapi3.Schema, rules string) (required bool) {
+	if rules == "" {
+		return false
+	}
+	split := strings.Split(rules, ",")
+	for i, rule := range split {
+		parts := strings.SplitN(rule, "=", 2)
+		param := ""
+		if len(parts) == 2 {
+			param = parts[1]
+		}
+		switch parts[0] {
+		case "required":
+			required = true
+		case "dive":
+			// the rules after dive are of the items
+			if schema.Items != nil && schema.Items.Ref == "" {
+				constrain(schema.Items.Value, strings.Join(split[i+1:], ","))
+			}
+			return required
+		case "email":
+			schema.WithFormat("email")
+		case "uuid", "uuid4":
+			schema.WithFormat("uuid")
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, v)
+			}
+		case "min", "max":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			constrainSize(schema, parts[0] == "min", n)
+		}
+	}
+	return required
+}
+
+func constrainSize(schema *openapi3.Schema, min bool, n float64) {
+	switch schema.Type {
+	case "string":
+		if min {
+			schema.WithMinLength(int64(n))
+		} else {
+			schema.WithMaxLength(int64(n))
+		}
+	case "array":
+		if min {
+			schema.WithMinItems(int64(n))
+		} else {
+			schema.WithMaxItems(int64(n))
+		}
+	default:
+		if min {
+			schema.WithMin(n)
+		} else {
+			schema.WithMax(n)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package openapi
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type (
+	schemaBase struct {
+		Id string `json:"id" validate:"required,uuid4"`
+	}
+	schemaNode struct {
+		Name     string        `json:"name"`
+		Children []*schemaNode `json:"children"`
+	}
+	schemaUser struct {
+		schemaBase
+		Name     *string           `json:"name,omitempty" validate:"required,min=1,max=255"`
+		Email    *string           `json:"email" validate:"omitempty,email"`
+		Role     string            `json:"role" validate:"oneof=admin reader"`
+		Age      int               `json:"age" validate:"min=18"`
+		Tags     []string          `json:"tags" validate:"max=5,dive,min=2"`
+		Avatar   []byte            `json:"avatar"`
+		Labels   map[string]string `json:"labels"`
+		Created  time.Time         `json:"created"`
+		Tree     schemaNode        `json:"tree"`
+		Any      interface{}       `json:"any"`
+		Password string            `json:"-"`
+		Untagged bool
+		internal string
+	}
+)
+
+func TestSchemaGenerator(t *testing.T) {
+	components := map[string]*openapi3.SchemaRef{}
+	ref := newSchemaGenerator(components).ref(reflect.TypeOf(&schemaUser{}))
+	if ref.Ref != "#/components/schemas/schemaUser" {
+		t.Fatalf("ref = %v, want a component reference", ref.Ref)
+	}
+	user := components["schemaUser"].Value
+	if user == nil || user.Type != "object" {
+		t.Fatalf("schemaUser = %v, want an object component", user)
+	}
+
+	if !reflect.DeepEqual(user.Required, []string{"id", "name"}) {
+		t.Errorf("required = %v, want id and name", user.Required)
+	}
+	for _, name := range []string{"Password", "-", "internal"} {
+		if _, ok := user.Properties[name]; ok {
+			t.Errorf("property %v, want it skipped", name)
+		}
+	}
+	property := func(name string) *openapi3.Schema {
+		ref, ok := user.Properties[name]
+		if !ok {
+			t.Fatalf("property %v missing", name)
+		}
+		return ref.Value
+	}
+
+	if id := property("id"); id.Type != "string" || id.Format != "uuid" {
+		t.Errorf("id = %v %v, want an uuid of the embedded struct", id.Type, id.Format)
+	}
+	if name := property("name"); name.MinLength != 1 || name.MaxLength == nil || *name.MaxLength != 255 || name.Nullable {
+		t.Errorf("name = %+v, want a required string of 1 to 255", name)
+	}
+	if email := property("email"); email.Format != "email" || !email.Nullable {
+		t.Errorf("email = %+v, want a nullable email", email)
+	}
+	if role := property("role"); !reflect.DeepEqual(role.Enum, []interface{}{"admin", "reader"}) {
+		t.Errorf("role enum = %v", role.Enum)
+	}
+	if age := property("age"); age.Format != "int32" || age.Min == nil || *age.Min != 18 {
+		t.Errorf("age = %+v, want an int32 of at least 18", age)
+	}
+	if tags := property("tags"); tags.Type != "array" || tags.MaxItems == nil || *tags.MaxItems != 5 || tags.Items.Value.MinLength != 2 {
+		t.Errorf("tags = %+v, want at most 5 items of at least 2 characters", tags)
+	}
+	if avatar := property("avatar"); avatar.Format != "byte" {
+		t.Errorf("avatar format = %v, want byte", avatar.Format)
+	}
+	if labels := property("labels"); labels.AdditionalProperties == nil || labels.AdditionalProperties.Value.Type != "string" {
+		t.Errorf("labels = %+v, want a map of strings", labels)
+	}
+	if created := property("created"); created.Type != "string" || created.Format != "date-time" {
+		t.Errorf("created = %v %v, want a date-time", created.Type, created.Format)
+	}
+	if untagged := property("Untagged"); untagged.Type != "boolean" {
+		t.Errorf("Untagged = %v, want a boolean named by the field", untagged.Type)
+	}
+	if any := property("any"); any.Type != "" {
+		t.Errorf("any = %v, want any type", any.Type)
+	}
+
+	if tree := user.Properties["tree"]; tree.Ref != "#/components/schemas/schemaNode" {
+		t.Errorf("tree = %v, want a component reference", tree.Ref)
+	}
+	node := components["schemaNode"].Value
+	if children := node.Properties["children"].Value; children.Items.Ref != "#/components/schemas/schemaNode" {
+		t.Errorf("children items = %v, want the recursive reference", children.Items.Ref)
+	}
+}
@@ -0,0 +1,43 @@
+package openapi
+
+import (
+	"bytes"
+	"embed"
+	"github.com/labstack/echo/v4"
+	"html/template"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed swaggerui.html
+var swaggerUI string
+
+//go:embed swaggerui/swagger-ui.css swaggerui/swagger-ui-bundle.js
+var swaggerUIAssets embed.FS
+
+var swaggerUITemplate = template.Must(template.New("swaggerui").Parse(swaggerUI))
+
+// UIHandler serves a Swagger UI page for the document at specURL with the assets at assetsURL.
+func UIHandler(title string, specURL string, assetsURL string) echo.HandlerFunc {
+	page := new(bytes.Buffer)
+	err := swaggerUITemplate.Execute(page, struct {
+		Title     string
+		SpecURL   string
+		AssetsURL string
+	}{Title: title, SpecURL: specURL, AssetsURL: assetsURL})
+	return func(c echo.Context) error {
+		if err != nil {
+			return err
+		}
+		return c.HTMLBlob(http.StatusOK, page.Bytes())
+	}
+}
+
+// AssetsHandler serves the Swagger UI assets under prefix.
+func AssetsHandler(prefix string) echo.HandlerFunc {
+	assets, err := fs.Sub(swaggerUIAssets, "swaggerui")
+	if err != nil {
+		panic(err)
+	}
+	return echo.WrapHandler(http.StripPrefix(prefix, http.FileServer(http.FS(assets))))
+}
@@ -0,0 +1,43 @@
+package openapi
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUIHandler(t *testing.T) {
+	e := echo.New()
+	e.GET("/docs", UIHandler("bpl", "/openapi.json", "/docs/assets"))
+	e.GET("/docs/assets/*", AssetsHandler("/docs/assets/"))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "https://") {
+		t.Errorf("page = %v %v, want no remote assets", rec.Code, rec.Body.String())
+	}
+	for _, want := range []string{`href="/docs/assets/swagger-ui.css"`, `src="/docs/assets/swagger-ui-bundle.js"`, `url: "\/openapi.json"`} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("page = %v, want it to contain %v", rec.Body.String(), want)
+		}
+	}
+
+	tests := map[string]string{
+		"/docs/assets/swagger-ui.css":       "text/css",
+		"/docs/assets/swagger-ui-bundle.js": "javascript",
+	}
+	for path, contentType := range tests {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get(echo.HeaderContentType), contentType) {
+			t.Errorf("%v = %v %v, want %v", path, rec.Code, rec.Header().Get(echo.HeaderContentType), contentType)
+		}
+	}
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/assets/LICENSE", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown asset = %v, want %v", rec.Code, http.StatusNotFound)
+	}
+}
@@ -16,7 +16,7 @@ import (
 )
 
 type (
-	spansService struct {
+	ServiceImpl struct {
 		services.NoopHealth
 		sis         services.Sis
 		ctx         context.Context
@@ -28,22 +28,38 @@ type (
 	}
 )
 
-func New() *spansService {
-	return &spansService{}
+func New() *ServiceImpl {
+	return &ServiceImpl{}
 }
 
-func (s *spansService) Init(ctx context.Context) error {
+func (s *ServiceImpl) WithHost(host string) *ServiceImpl {
+	s.host = host
+	return s
+}
+
+func (s *ServiceImpl) WithServiceName(serviceName string) *ServiceImpl {
+	s.serviceName = serviceName
+	return s
+}
+
+func (s *ServiceImpl) Init(ctx context.Context) error {
 	s.ctx = ctx
 	if s.tracer == nil {
 		if s.host == "" {
 			s.host = s.Sis().Environment().SpanUrl()
 		}
-		s.reporter = http.NewReporter(s.host, http.BatchInterval(time.Second*3))
+		// without a collector the tracer is noop, spans are still created so cids and ids flow
+		if s.host != "" {
+			s.reporter = http.NewReporter(s.host, http.BatchInterval(time.Second*3))
+		}
 
 		if s.serviceName == "" {
 			s.serviceName = s.Sis().Environment().Service()
 		}
-		// create our local spansService endpoint
+		if s.version == "" {
+			s.version = s.Sis().Environment().Version()
+		}
+		// create our local ServiceImpl endpoint
 		endpoint, err := zipkin.NewEndpoint(s.serviceName, "0.0.0.0:8080")
 		if err != nil {
 			return err
@@ -64,23 +80,26 @@ func (s *spansService) Init(ctx context.Context) error {
 	return nil
 }
 
-func (s *spansService) Close() error {
+func (s *ServiceImpl) Close() error {
+	if s.reporter == nil {
+		return nil
+	}
 	if err := s.reporter.Close(); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (s *spansService) WithSis(c services.Sis) services.Spans {
+func (s *ServiceImpl) WithSis(c services.Sis) services.Spans {
 	s.sis = c
 	return s
 }
 
-func (s *spansService) Sis() services.Sis {
+func (s *ServiceImpl) Sis() services.Sis {
 	return s.sis
 }
 
-func (s *spansService) New(ctx context.Context, spanConfigs ...structs.SpanConfig) (context.Context, *structs.Span) {
+func (s *ServiceImpl) New(ctx context.Context, spanConfigs ...structs.SpanConfig) (context.Context, *structs.Span) {
 	var cid string
 	if c := ctxs.GetCidFromContext(ctx); c != nil {
 		cid = *c
@@ -121,6 +140,6 @@ func (s *spansService) New(ctx context.Context, spanConfigs ...structs.SpanConfi
 	return ctx, sp
 }
 
-func (s *spansService) Tracer() *zipkin.Tracer {
+func (s *ServiceImpl) Tracer() *zipkin.Tracer {
 	return s.tracer
 }
@@ -2,35 +2,30 @@ package spans
 
 import (
 	"context"
-	"github.com/openzipkin/zipkin-go"
-	"github.com/openzipkin/zipkin-go/reporter/http"
-	"github.com/dalmarcogd/bpl-go/internal/structs"
 	"github.com/dalmarcogd/bpl-go/internal/infra/ctxs"
-	"github.com/dalmarcogd/bpl-go/internal/infra/validator"
 	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/openzipkin/zipkin-go"
 	"testing"
 )
 
-func TestSpansService_New(t *testing.T) {
+func TestServiceImpl_New(t *testing.T) {
 	serviceImpl := New()
-	sm := services.New().WithValidator(validator.New()).WithSpans(serviceImpl)
+	sm := services.New().WithSpans(serviceImpl)
 
 	if err := sm.Init(); err != nil {
-		t.Error("unexpected error")
-	}
-	var err error
-	serviceImpl.tracer, err = zipkin.NewTracer(
-		http.NewReporter("localhost:8080"),
-		zipkin.WithNoopTracer(true),
-		zipkin.WithNoopSpan(true),
-	)
-	if err != nil {
 		t.Error(err)
 	}
 
-	_, span := sm.Spans().New(ctxs.ContextWithCid(context.Background(), "mycid"), structs.WithOrgId("myorgid"))
+	ctx, span := sm.Spans().New(ctxs.ContextWithCid(context.Background(), "mycid"), structs.WithOrgId("myorgid"))
+	if span.Cid != "mycid" || span.OrgId != "myorgid" {
+		t.Errorf("span = %+v, want cid and org id", span)
+	}
+	if zipkin.SpanFromContext(ctx) == nil {
+		t.Error("expected the span on the context")
+	}
 	span.Finish()
-	if err := serviceImpl.ServiceManager().Close(); err != nil {
+	if err := serviceImpl.Sis().Close(); err != nil {
 		t.Error(err)
 	}
 }
@@ -3,14 +3,17 @@ package validator
 import (
 	"context"
 	goerrors "errors"
-	"github.com/go-playground/validator/v10"
+	"fmt"
 	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"github.com/dalmarcogd/bpl-go/internal/models"
 	"github.com/dalmarcogd/bpl-go/internal/services"
+	"github.com/go-playground/validator/v10"
 	"reflect"
+	"strings"
 )
 
 type (
-	validatorService struct {
+	ServiceImpl struct {
 		services.NoopHealth
 		serviceManager services.Sis
 		ctx            context.Context
@@ -18,33 +21,35 @@ type (
 	}
 )
 
-func New() *validatorService {
-	return &validatorService{}
+func New() *ServiceImpl {
+	return &ServiceImpl{}
 }
 
-func (s *validatorService) Init(ctx context.Context) error {
+func (s *ServiceImpl) Init(ctx context.Context) error {
 	s.ctx = ctx
 	if s.validate == nil {
 		s.validate = validator.New()
+		// report fields by the name clients send them
+		s.validate.RegisterTagNameFunc(jsonFieldName)
 	}
 
 	return nil
 }
 
-func (s *validatorService) Close() error {
+func (s *ServiceImpl) Close() error {
 	return nil
 }
 
-func (s *validatorService) WithServiceManager(c services.ServiceManager) services.Validator {
+func (s *ServiceImpl) WithSis(c services.Sis) services.Validator {
 	s.serviceManager = c
 	return s
 }
 
-func (s *validatorService) ServiceManager() services.ServiceManager {
+func (s *ServiceImpl) Sis() services.Sis {
 	return s.serviceManager
 }
 
-func (s *validatorService) Validate(ctx context.Context, obj interface{}) error {
+func (s *ServiceImpl) Validate(ctx context.Context, obj interface{}) error {
 	err := s.validate.StructCtx(ctx, obj)
 	if err != nil {
 		return err
@@ -52,31 +57,91 @@ func (s *validatorService) Validate(ctx context.Context, obj interface{}) error
 	return nil
 }
 
-func (s *validatorService) ValidateSlice(ctx context.Context, objs interface{}) error {
+func (s *ServiceImpl) ValidateSlice(ctx context.Context, objs interface{}) error {
 	sv := reflect.ValueOf(objs)
 	if sv.Kind() != reflect.Slice || sv.IsNil() {
-		return errors.ObjsIsNotSliceValidatorError
-	}
-
-	ret := make([]interface{}, sv.Len())
-	for i := 0; i < sv.Len(); i++ {
-		ret[i] = sv.Index(i).Interface()
+		return errors.ValidatorObjsNotSlice
 	}
 
 	var validationErrors validator.ValidationErrors
-	for _, obj := range ret {
-		err := s.Validate(ctx, obj)
+	for i := 0; i < sv.Len(); i++ {
+		err := s.Validate(ctx, sv.Index(i).Interface())
 		if err != nil {
-			if goerrors.Is(err, validator.ValidationErrors{}) {
-				validationErrors = append(validationErrors, err.(validator.ValidationErrors)...)
-			} else {
+			var errs validator.ValidationErrors
+			if !goerrors.As(err, &errs) {
 				return err
 			}
+			validationErrors = append(validationErrors, errs...)
 		}
 	}
-	ret = nil
 	if len(validationErrors) > 0 {
 		return validationErrors
 	}
 	return nil
 }
+
+// FieldErrors describes each field of a validation error.
+func FieldErrors(err error) (fields []models.FieldError, ok bool) {
+	var errs validator.ValidationErrors
+	if !goerrors.As(err, &errs) {
+		return nil, false
+	}
+	for _, e := range errs {
+		fields = append(fields, models.FieldError{
+			Field:   fieldPath(e),
+			Rule:    e.Tag(),
+			Message: fieldMessage(e),
+		})
+	}
+	return fields, true
+}
+
+func fieldPath(e validator.FieldError) string {
+	ns := e.Namespace()
+	if dot := strings.IndexByte(ns, '.'); dot >= 0 {
+		return ns[dot+1:]
+	}
+	return ns
+}
+
+func fieldMessage(e validator.FieldError) string {
+	switch e.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email"
+	case "uuid", "uuid4":
+		return "must be a valid uuid"
+	case "url":
+		return "must be a valid url"
+	case "min":
+		if e.Kind() == reflect.String && e.Param() == "1" {
+			return "must not be empty"
+		}
+		if e.Kind() == reflect.String {
+			return fmt.Sprintf("must have at least %v characters", e.Param())
+		}
+		return fmt.Sprintf("must be at least %v", e.Param())
+	case "max":
+		if e.Kind() == reflect.String {
+			return fmt.Sprintf("must have at most %v characters", e.Param())
+		}
+		return fmt.Sprintf("must be at most %v", e.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %v", e.Param())
+	default:
+		return fmt.Sprintf("must satisfy %v", e.Tag())
+	}
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	default:
+		return name
+	}
+}
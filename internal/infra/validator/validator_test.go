@@ -2,7 +2,9 @@ package validator
 
 import (
 	"context"
+	"github.com/dalmarcogd/bpl-go/internal/models"
 	"github.com/dalmarcogd/bpl-go/internal/services"
+	"reflect"
 	"testing"
 )
 
@@ -48,7 +50,29 @@ func TestValidatorError(t *testing.T) {
 		t.Error("expected error from validator")
 	}
 
-	if err := serviceImpl.ServiceManager().Close(); err != nil {
+	if err := serviceImpl.Sis().Close(); err != nil {
 		t.Error(err)
 	}
 }
+
+func TestFieldErrors(t *testing.T) {
+	serviceImpl := New()
+	if err := serviceImpl.Init(context.Background()); err != nil {
+		t.Error(err)
+	}
+
+	name := ""
+	email := "not-an-email"
+	err := serviceImpl.Validate(context.Background(), &models.UserRequest{Name: &name, Email: &email})
+	fields, ok := FieldErrors(err)
+	if !ok {
+		t.Fatalf("FieldErrors(%v) is not a validation error", err)
+	}
+	want := []models.FieldError{
+		{Field: "name", Rule: "min", Message: "must not be empty"},
+		{Field: "email", Rule: "email", Message: "must be a valid email"},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("FieldErrors() = %v, want %v", fields, want)
+	}
+}
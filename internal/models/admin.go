@@ -0,0 +1,21 @@
+package models
+
+type (
+	Route struct {
+		Method string `json:"method"`
+		Path   string `json:"path"`
+		Name   string `json:"name"`
+	}
+	HealthResponse struct {
+		Status string  `json:"status"`
+		Error  *string `json:"error,omitempty"`
+	}
+	BuildInfoResponse struct {
+		Service       string `json:"service"`
+		Version       string `json:"version"`
+		Environment   string `json:"environment"`
+		GoVersion     string `json:"goVersion"`
+		Module        string `json:"module"`
+		ModuleVersion string `json:"moduleVersion"`
+	}
+)
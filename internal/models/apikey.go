@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+)
+
+type (
+	ApiKeyRequest struct {
+		Name      *string    `json:"name" validate:"required,min=1,max=255"`
+		Scopes    []string   `json:"scopes" validate:"required,min=1,max=32,dive,min=1,max=64"`
+		Tenant    *string    `json:"tenant" validate:"omitempty,max=255"`
+		ExpiresAt *time.Time `json:"expiresAt"`
+	}
+	ApiKeyResponse struct {
+		Id         string     `json:"id"`
+		Name       string     `json:"name"`
+		Prefix     string     `json:"prefix"`
+		Scopes     []string   `json:"scopes"`
+		Tenant     *string    `json:"tenant"`
+		CreatedBy  *string    `json:"createdBy"`
+		CreatedAt  time.Time  `json:"createdAt"`
+		ExpiresAt  *time.Time `json:"expiresAt"`
+		LastUsedAt *time.Time `json:"lastUsedAt"`
+		RevokedAt  *time.Time `json:"revokedAt"`
+	}
+	// ApiKeyCreatedResponse is the only response with the key.
+	ApiKeyCreatedResponse struct {
+		ApiKeyResponse
+		Key string `json:"key"`
+	}
+	// ApiKey keeps the sha256 Hash of the key, it is found by its Prefix.
+	ApiKey struct {
+		Id         string `gorm:"primarykey"`
+		Name       string
+		Prefix     string `gorm:"uniqueIndex"`
+		Hash       string
+		Scopes     string
+		Tenant     *string
+		CreatedBy  *string
+		CreatedAt  time.Time
+		UpdatedAt  time.Time
+		ExpiresAt  *time.Time
+		LastUsedAt *time.Time
+		RevokedAt  *time.Time
+	}
+)
@@ -0,0 +1,13 @@
+package models
+
+type (
+	LogLevelRequest struct {
+		Package *string `json:"package"`
+		Level   *string `json:"level"`
+	}
+	LogLevelsResponse struct {
+		Level    string            `json:"level"`
+		Packages map[string]string `json:"packages"`
+		Dropped  map[string]uint64 `json:"dropped"`
+	}
+)
@@ -0,0 +1,15 @@
+package models
+
+type (
+	// Problem is an RFC 7807 problem details body.
+	Problem struct {
+		Type     string                 `json:"type"`
+		Title    string                 `json:"title"`
+		Status   int                    `json:"status"`
+		Detail   string                 `json:"detail,omitempty"`
+		Instance string                 `json:"instance,omitempty"`
+		Code     string                 `json:"code,omitempty"`
+		Cid      string                 `json:"cid,omitempty"`
+		Details  map[string]interface{} `json:"details,omitempty"`
+	}
+)
@@ -7,11 +7,11 @@ import (
 
 type (
 	UserRequest struct {
-		Name  *string `json:"name"`
-		Email *string `json:"email"`
+		Name  *string `json:"name" validate:"required,min=1,max=255"`
+		Email *string `json:"email" validate:"required,email,max=255"`
 	}
 	UserResponse struct {
-		Id    string `json:"id"`
+		Id    string  `json:"id"`
 		Name  *string `json:"name"`
 		Email *string `json:"email"`
 	}
@@ -25,4 +25,3 @@ type (
 		DeletedAt gorm.DeletedAt `gorm:"index"`
 	}
 )
-
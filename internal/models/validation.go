@@ -0,0 +1,9 @@
+package models
+
+type (
+	FieldError struct {
+		Field   string `json:"field"`
+		Rule    string `json:"rule"`
+		Message string `json:"message"`
+	}
+)
@@ -2,8 +2,13 @@ package services
 
 import (
 	"context"
-	"database/sql"
+	"github.com/dalmarcogd/bpl-go/internal/errors"
 	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/openzipkin/zipkin-go"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
 )
 
 type (
@@ -18,18 +23,32 @@ type (
 	NoopHttpServer struct {
 		NoopHealth
 	}
+	NoopAdminServer struct {
+		NoopHealth
+	}
 	NoopCache struct {
 		NoopHealth
 	}
+	NoopLock struct {
+		key string
+	}
 	NoopLogger struct {
 		NoopHealth
 	}
+	NoopSpans struct {
+		NoopHealth
+	}
+	NoopMetrics struct {
+		NoopHealth
+	}
 	NoopHandlers struct {
 		NoopHealth
 	}
 	NoopEnvironment struct {
 		NoopHealth
 	}
+	// NoopCollector is the Counter, Gauge and Histogram of NoopMetrics.
+	NoopCollector struct{}
 )
 
 func (n *NoopHealth) Health(_ context.Context) error {
@@ -84,104 +103,125 @@ func (n *NoopDatabase) WithSis(_ Sis) Database {
 	return n
 }
 
-func (n *NoopDatabase) WithCardsAutomaticUpdaterDatabase() Database {
-	return n
-}
-
-func (n *NoopDatabase) WithCardsEmbossingsDatabase() Database {
-	return n
+// DB returns nil, there is no database to query.
+func (n *NoopDatabase) DB(_ context.Context) *gorm.DB {
+	return nil
 }
 
-func (n *NoopDatabase) WithCoreDatabase() Database {
-	return n
+func NewNoopHttpServer() *NoopHttpServer {
+	return &NoopHttpServer{}
 }
 
-func (n *NoopDatabase) WithMasterClient(_ *sql.DB) Database {
+func (n *NoopHttpServer) Sis() Sis {
 	return nil
 }
 
-func (n *NoopDatabase) WithReplicaClient(_ *sql.DB) Database {
+func (n *NoopHttpServer) Init(_ context.Context) error {
 	return nil
 }
 
-func (n *NoopDatabase) Ping(_ context.Context) error {
+func (n *NoopHttpServer) Close() error {
 	return nil
 }
 
-func (n *NoopDatabase) OpenTransactionMaster(ctx context.Context) (context.Context, error) {
-	return ctx, nil
+func (n *NoopHttpServer) WithSis(_ Sis) HttpServer {
+	return n
 }
 
-func (n *NoopDatabase) TransactionMaster(context.Context, func(tx DatabaseTransaction) error) error {
+func (n *NoopHttpServer) Run() error {
 	return nil
 }
 
-func (n *NoopDatabase) OpenTransactionReplica(ctx context.Context) (context.Context, error) {
-	return ctx, nil
+func (n *NoopHttpServer) Routes() []models.Route {
+	return []models.Route{}
+}
+
+func NewNoopAdminServer() *NoopAdminServer {
+	return &NoopAdminServer{}
 }
 
-func (n *NoopDatabase) TransactionReplica(context.Context, func(tx DatabaseTransaction) error) error {
+func (n *NoopAdminServer) Sis() Sis {
 	return nil
 }
 
-func (n *NoopDatabase) CloseTransaction(_ context.Context, err error) error {
-	return err
+func (n *NoopAdminServer) Init(_ context.Context) error {
+	return nil
 }
 
-func (n *NoopDatabase) Insert(_ context.Context, _ string, _ ...interface{}) (sql.Result, error) {
-	return nil, nil
+func (n *NoopAdminServer) Close() error {
+	return nil
 }
 
-func (n *NoopDatabase) Update(_ context.Context, _ string, _ ...interface{}) (sql.Result, error) {
-	return nil, nil
+func (n *NoopAdminServer) WithSis(_ Sis) AdminServer {
+	return n
 }
 
-func (n *NoopDatabase) Get(_ context.Context, _ string, _ ...interface{}) (*sql.Rows, error) {
-	return nil, nil
+func (n *NoopAdminServer) Run() error {
+	return nil
 }
 
-func NewNoopHttpServer() *NoopHttpServer {
-	return &NoopHttpServer{}
+func NewNoopCache() *NoopCache {
+	return &NoopCache{}
 }
 
-func (n *NoopHttpServer) Sis() Sis {
+func (n *NoopCache) Sis() Sis {
 	return nil
 }
 
-func (n *NoopHttpServer) Init(_ context.Context) error {
+func (n *NoopCache) Init(_ context.Context) error {
 	return nil
 }
 
-func (n *NoopHttpServer) Close() error {
+func (n *NoopCache) Close() error {
 	return nil
 }
 
-func (n *NoopHttpServer) WithSis(_ Sis) HttpServer {
+func (n *NoopCache) WithSis(_ Sis) Cache {
 	return n
 }
 
-func (n *NoopHttpServer) Run() error {
-	return nil
+func (n *NoopCache) Get(_ context.Context, _ string) ([]byte, error) {
+	return nil, errors.CacheKeyNotFound
 }
 
-func NewNoopCache() *NoopCache {
-	return &NoopCache{}
+func (n *NoopCache) Set(_ context.Context, _ string, _ []byte, _ time.Duration) error {
+	return nil
 }
 
-func (n *NoopCache) Sis() Sis {
+func (n *NoopCache) Delete(_ context.Context, _ ...string) error {
 	return nil
 }
 
-func (n *NoopCache) Init(_ context.Context) error {
-	return nil
+func (n *NoopCache) GetOrLoad(ctx context.Context, _ string, _ time.Duration, loader CacheLoader) ([]byte, error) {
+	return loader(ctx)
 }
 
-func (n *NoopCache) Close() error {
+func (n *NoopCache) Lock(_ context.Context, key string, _ time.Duration) (Lock, error) {
+	return &NoopLock{key: key}, nil
+}
+
+func (n *NoopCache) RateLimit(_ context.Context, _ string, limit structs.RateLimit) (*structs.RateLimitResult, error) {
+	return &structs.RateLimitResult{Allowed: true, Limit: limit.Limit, Remaining: limit.Limit}, nil
+}
+
+func (n *NoopLock) Key() string {
+	return n.key
+}
+
+func (n *NoopLock) Token() string {
+	return ""
+}
+
+func (n *NoopLock) FencingToken() int64 {
+	return 0
+}
+
+func (n *NoopLock) Lost() <-chan struct{} {
 	return nil
 }
 
-func (n *NoopCache) WithSis(_ Sis) Cache {
-	return n
+func (n *NoopLock) Release(_ context.Context) error {
+	return nil
 }
 
 func NewNoopLogger() *NoopLogger {
@@ -204,6 +244,38 @@ func (n *NoopLogger) WithSis(_ Sis) Logger {
 	return n
 }
 
+func (n *NoopLogger) With(_ map[string]interface{}) Logger {
+	return n
+}
+
+func (n *NoopLogger) WithCallerSkip(_ int) Logger {
+	return n
+}
+
+func (n *NoopLogger) Level() string {
+	return ""
+}
+
+func (n *NoopLogger) PackageLevels() map[string]string {
+	return map[string]string{}
+}
+
+func (n *NoopLogger) SetLevel(_ string) error {
+	return nil
+}
+
+func (n *NoopLogger) SetPackageLevel(_ string, _ string) error {
+	return nil
+}
+
+func (n *NoopLogger) Dropped() map[string]uint64 {
+	return map[string]uint64{}
+}
+
+func (n *NoopLogger) Trace(_ context.Context, _ string, _ ...map[string]interface{}) {}
+
+func (n *NoopLogger) Debug(_ context.Context, _ string, _ ...map[string]interface{}) {}
+
 func (n *NoopLogger) Info(_ context.Context, _ string, _ ...map[string]interface{}) {}
 
 func (n *NoopLogger) Warn(_ context.Context, _ string, _ ...map[string]interface{}) {}
@@ -212,6 +284,83 @@ func (n *NoopLogger) Error(_ context.Context, _ string, _ ...map[string]interfac
 
 func (n *NoopLogger) Fatal(_ context.Context, _ string, _ ...map[string]interface{}) {}
 
+func NewNoopSpans() *NoopSpans {
+	return &NoopSpans{}
+}
+
+func (n *NoopSpans) Sis() Sis {
+	return nil
+}
+
+func (n *NoopSpans) Init(_ context.Context) error {
+	return nil
+}
+
+func (n *NoopSpans) Close() error {
+	return nil
+}
+
+func (n *NoopSpans) WithSis(_ Sis) Spans {
+	return n
+}
+
+func (n *NoopSpans) New(ctx context.Context, _ ...structs.SpanConfig) (context.Context, *structs.Span) {
+	return ctx, &structs.Span{Custom: map[string]interface{}{}}
+}
+
+// Tracer returns nil, callers skip tracing without a Tracer.
+func (n *NoopSpans) Tracer() *zipkin.Tracer {
+	return nil
+}
+
+func NewNoopMetrics() *NoopMetrics {
+	return &NoopMetrics{}
+}
+
+func (n *NoopMetrics) Sis() Sis {
+	return nil
+}
+
+func (n *NoopMetrics) Init(_ context.Context) error {
+	return nil
+}
+
+func (n *NoopMetrics) Close() error {
+	return nil
+}
+
+func (n *NoopMetrics) WithSis(_ Sis) Metrics {
+	return n
+}
+
+func (n *NoopMetrics) Counter(_ string, _ string, _ ...string) Counter {
+	return &NoopCollector{}
+}
+
+func (n *NoopMetrics) Gauge(_ string, _ string, _ ...string) Gauge {
+	return &NoopCollector{}
+}
+
+func (n *NoopMetrics) Histogram(_ string, _ string, _ []float64, _ ...string) Histogram {
+	return &NoopCollector{}
+}
+
+func (n *NoopMetrics) CounterFunc(_ string, _ string, _ func() float64) {}
+
+func (n *NoopMetrics) GaugeFunc(_ string, _ string, _ func() float64) {}
+
+func (n *NoopMetrics) Handler() http.Handler {
+	return http.NotFoundHandler()
+}
+
+func (n *NoopCollector) Inc(_ ...string) {}
+
+func (n *NoopCollector) Add(_ float64, _ ...string) {}
+
+func (n *NoopCollector) Set(_ float64, _ ...string) {}
+
+func (n *NoopCollector) Observe(_ float64, _ ...string) {}
+
 func NewNoopHandlers() *NoopHandlers {
 	return &NoopHandlers{}
 }
@@ -252,6 +401,22 @@ func (n *NoopHandlers) DeleteUser(_ context.Context, _ *models.User) error {
 	return nil
 }
 
+func (n *NoopHandlers) CreateApiKey(_ context.Context, _ *models.ApiKey) (string, error) {
+	return "", nil
+}
+
+func (n *NoopHandlers) GetApiKeys(_ context.Context, _ *[]models.ApiKey) error {
+	return nil
+}
+
+func (n *NoopHandlers) RevokeApiKey(_ context.Context, _ *models.ApiKey) error {
+	return nil
+}
+
+func (n *NoopHandlers) AuthenticateApiKey(_ context.Context, _ string) (*models.ApiKey, error) {
+	return nil, nil
+}
+
 func NewNoopEnvironment() *NoopEnvironment {
 	return &NoopEnvironment{}
 }
@@ -284,14 +449,342 @@ func (n *NoopEnvironment) Version() string {
 	return ""
 }
 
+func (n *NoopEnvironment) SpanUrl() string {
+	return ""
+}
+
+func (n *NoopEnvironment) OpenAPIValidationEnabled() bool {
+	return false
+}
+
+func (n *NoopEnvironment) Redacted() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
 func (n *NoopEnvironment) DebugPprof() bool {
 	return false
 }
 
+func (n *NoopEnvironment) HttpAddress() string {
+	return ""
+}
+
+func (n *NoopEnvironment) HttpTLSCertFile() string {
+	return ""
+}
+
+func (n *NoopEnvironment) HttpTLSKeyFile() string {
+	return ""
+}
+
+func (n *NoopEnvironment) HttpTLSReloadInterval() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) HttpTLSMinVersion() string {
+	return ""
+}
+
+func (n *NoopEnvironment) HttpTLSCiphers() []string {
+	return []string{}
+}
+
+func (n *NoopEnvironment) HttpTLSClientCAFile() string {
+	return ""
+}
+
+func (n *NoopEnvironment) HttpTLSClientAuth() string {
+	return ""
+}
+
+func (n *NoopEnvironment) HttpHTTP2() bool {
+	return false
+}
+
+func (n *NoopEnvironment) HttpRedirectAddress() string {
+	return ""
+}
+
+func (n *NoopEnvironment) HttpReadHeaderTimeout() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) HttpReadTimeout() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) HttpWriteTimeout() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) HttpIdleTimeout() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) HttpMaxHeaderBytes() int {
+	return 0
+}
+
+func (n *NoopEnvironment) HttpMaxBodyBytes() int64 {
+	return 0
+}
+
+func (n *NoopEnvironment) HttpTrustedProxies() []string {
+	return []string{}
+}
+
+func (n *NoopEnvironment) HttpCORSAllowOrigins() []string {
+	return []string{}
+}
+
+func (n *NoopEnvironment) HttpCORSAllowCredentials() bool {
+	return false
+}
+
+func (n *NoopEnvironment) HttpCORSMaxAge() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) HttpHSTSMaxAge() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) HttpHSTSIncludeSubdomains() bool {
+	return false
+}
+
+func (n *NoopEnvironment) HttpFrameOptions() string {
+	return ""
+}
+
+func (n *NoopEnvironment) HttpContentTypeNosniff() bool {
+	return false
+}
+
+func (n *NoopEnvironment) AdminAddress() string {
+	return ""
+}
+
 func (n *NoopEnvironment) DatabaseDsn() string {
 	return ""
 }
 
-func (n *NoopEnvironment) CacheAddress() string {
+func (n *NoopEnvironment) CacheMode() string {
+	return ""
+}
+
+func (n *NoopEnvironment) CacheAddresses() []string {
+	return nil
+}
+
+func (n *NoopEnvironment) CacheUsername() string {
+	return ""
+}
+
+func (n *NoopEnvironment) CachePassword() string {
 	return ""
 }
+
+func (n *NoopEnvironment) CacheDB() int {
+	return 0
+}
+
+func (n *NoopEnvironment) CacheMasterName() string {
+	return ""
+}
+
+func (n *NoopEnvironment) CacheSentinelPassword() string {
+	return ""
+}
+
+func (n *NoopEnvironment) CacheTLS() bool {
+	return false
+}
+
+func (n *NoopEnvironment) CacheTLSCAFile() string {
+	return ""
+}
+
+func (n *NoopEnvironment) CacheTLSServerName() string {
+	return ""
+}
+
+func (n *NoopEnvironment) CacheDialTimeout() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) CacheReadTimeout() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) CacheWriteTimeout() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) CachePoolSize() int {
+	return 0
+}
+
+func (n *NoopEnvironment) CacheMinIdleConns() int {
+	return 0
+}
+
+func (n *NoopEnvironment) CacheStaleTTL() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) CacheTTLJitter() float64 {
+	return 0
+}
+
+func (n *NoopEnvironment) CacheRefreshLock() bool {
+	return false
+}
+
+func (n *NoopEnvironment) RateLimitEnabled() bool {
+	return false
+}
+
+func (n *NoopEnvironment) RateLimitAlgorithm() string {
+	return ""
+}
+
+func (n *NoopEnvironment) RateLimitLimit() int {
+	return 0
+}
+
+func (n *NoopEnvironment) RateLimitPeriod() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) RateLimitRoutes() []string {
+	return []string{}
+}
+
+func (n *NoopEnvironment) RateLimitKey() string {
+	return ""
+}
+
+func (n *NoopEnvironment) AuthEnabled() bool {
+	return false
+}
+
+func (n *NoopEnvironment) AuthJWKSFile() string {
+	return ""
+}
+
+func (n *NoopEnvironment) AuthJWKSUrl() string {
+	return ""
+}
+
+func (n *NoopEnvironment) AuthJWKSCacheTTL() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) AuthJWTIssuer() string {
+	return ""
+}
+
+func (n *NoopEnvironment) AuthJWTAudience() string {
+	return ""
+}
+
+func (n *NoopEnvironment) AuthRolesClaim() string {
+	return ""
+}
+
+func (n *NoopEnvironment) AuthTenantClaim() string {
+	return ""
+}
+
+func (n *NoopEnvironment) AuthApiKeysEnabled() bool {
+	return false
+}
+
+func (n *NoopEnvironment) LogLevel() string {
+	return ""
+}
+
+func (n *NoopEnvironment) LogPackageLevels() []string {
+	return nil
+}
+
+func (n *NoopEnvironment) LogStdoutLevel() string {
+	return ""
+}
+
+func (n *NoopEnvironment) LogStdoutFormat() string {
+	return ""
+}
+
+func (n *NoopEnvironment) LogFilePath() string {
+	return ""
+}
+
+func (n *NoopEnvironment) LogFileLevel() string {
+	return ""
+}
+
+func (n *NoopEnvironment) LogFileFormat() string {
+	return ""
+}
+
+func (n *NoopEnvironment) LogFileMaxSizeMB() int {
+	return 0
+}
+
+func (n *NoopEnvironment) LogFileMaxAgeDays() int {
+	return 0
+}
+
+func (n *NoopEnvironment) LogFileMaxBackups() int {
+	return 0
+}
+
+func (n *NoopEnvironment) LogFileCompress() bool {
+	return false
+}
+
+func (n *NoopEnvironment) LogFileRotateInterval() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) LogSyslogAddress() string {
+	return ""
+}
+
+func (n *NoopEnvironment) LogSyslogLevel() string {
+	return ""
+}
+
+func (n *NoopEnvironment) LogSyslogFormat() string {
+	return ""
+}
+
+func (n *NoopEnvironment) LogSamplingEnabled() bool {
+	return false
+}
+
+func (n *NoopEnvironment) LogSamplingFirst() int {
+	return 0
+}
+
+func (n *NoopEnvironment) LogSamplingThereafter() int {
+	return 0
+}
+
+func (n *NoopEnvironment) LogSamplingInterval() time.Duration {
+	return 0
+}
+
+func (n *NoopEnvironment) LogRedactEnabled() bool {
+	return false
+}
+
+func (n *NoopEnvironment) LogRedactKeys() []string {
+	return nil
+}
+
+func (n *NoopEnvironment) LogRedactPatterns() []string {
+	return nil
+}
@@ -2,9 +2,13 @@ package services
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"github.com/dalmarcogd/bpl-go/internal/models"
+	"github.com/dalmarcogd/bpl-go/internal/structs"
+	"github.com/openzipkin/zipkin-go"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
 )
 
 type (
@@ -17,19 +21,7 @@ type (
 	Database interface {
 		Generic
 		WithSis(c Sis) Database
-		WithCoreDatabase() Database
-		WithMasterClient(*sql.DB) Database
-		WithReplicaClient(*sql.DB) Database
-		OpenTransactionMaster(ctx context.Context) (context.Context, error)
-		TransactionMaster(ctx context.Context, f func(tx DatabaseTransaction) error) error
-		OpenTransactionReplica(ctx context.Context) (context.Context, error)
-		TransactionReplica(ctx context.Context, f func(tx DatabaseTransaction) error) error
-		CloseTransaction(ctx context.Context, err error) error
-	}
-	DatabaseTransaction interface {
-		Insert(query string, args ...interface{}) (sql.Result, error)
-		Update(query string, args ...interface{}) (sql.Result, error)
-		Get(query string, args ...interface{}) (*sql.Rows, error)
+		DB(ctx context.Context) *gorm.DB
 	}
 	Validator interface {
 		Generic
@@ -40,29 +32,168 @@ type (
 	Cache interface {
 		Generic
 		WithSis(c Sis) Cache
+		Get(ctx context.Context, key string) ([]byte, error)
+		Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+		Delete(ctx context.Context, keys ...string) error
+		GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader CacheLoader) ([]byte, error)
+		Lock(ctx context.Context, key string, ttl time.Duration) (Lock, error)
+		RateLimit(ctx context.Context, key string, limit structs.RateLimit) (*structs.RateLimitResult, error)
+	}
+	CacheLoader func(ctx context.Context) ([]byte, error)
+	Lock        interface {
+		Key() string
+		Token() string
+		FencingToken() int64
+		Lost() <-chan struct{}
+		Release(ctx context.Context) error
 	}
 	Logger interface {
 		Generic
 		WithSis(c Sis) Logger
+		With(fields map[string]interface{}) Logger
+		WithCallerSkip(skip int) Logger
+		Level() string
+		PackageLevels() map[string]string
+		SetLevel(level string) error
+		SetPackageLevel(pkg string, level string) error
+		Dropped() map[string]uint64
+		Trace(ctx context.Context, message string, fields ...map[string]interface{})
+		Debug(ctx context.Context, message string, fields ...map[string]interface{})
 		Info(ctx context.Context, message string, fields ...map[string]interface{})
 		Warn(ctx context.Context, message string, fields ...map[string]interface{})
 		Error(ctx context.Context, message string, fields ...map[string]interface{})
 		Fatal(ctx context.Context, message string, fields ...map[string]interface{})
 	}
+	Metrics interface {
+		Generic
+		WithSis(c Sis) Metrics
+		Counter(name string, help string, labels ...string) Counter
+		Gauge(name string, help string, labels ...string) Gauge
+		Histogram(name string, help string, buckets []float64, labels ...string) Histogram
+		CounterFunc(name string, help string, f func() float64)
+		GaugeFunc(name string, help string, f func() float64)
+		Handler() http.Handler
+	}
+	// Counter, Gauge and Histogram take the values of the labels they were created with, in order.
+	Counter interface {
+		Inc(labelValues ...string)
+		Add(v float64, labelValues ...string)
+	}
+	Gauge interface {
+		Set(v float64, labelValues ...string)
+		Add(v float64, labelValues ...string)
+	}
+	Histogram interface {
+		Observe(v float64, labelValues ...string)
+	}
+	Spans interface {
+		Generic
+		WithSis(c Sis) Spans
+		New(ctx context.Context, spanConfigs ...structs.SpanConfig) (context.Context, *structs.Span)
+		Tracer() *zipkin.Tracer
+	}
 	HttpServer interface {
 		Generic
 		WithSis(c Sis) HttpServer
 		Run() error
+		Routes() []models.Route
+	}
+	AdminServer interface {
+		Generic
+		WithSis(c Sis) AdminServer
+		Run() error
 	}
 	Environment interface {
 		Generic
 		WithSis(c Sis) Environment
+		Redacted() map[string]interface{}
 		Environment() string
 		Service() string
 		Version() string
 		DebugPprof() bool
+		HttpAddress() string
+		HttpTLSCertFile() string
+		HttpTLSKeyFile() string
+		HttpTLSReloadInterval() time.Duration
+		HttpTLSMinVersion() string
+		HttpTLSCiphers() []string
+		HttpTLSClientCAFile() string
+		HttpTLSClientAuth() string
+		HttpHTTP2() bool
+		HttpRedirectAddress() string
+		HttpReadHeaderTimeout() time.Duration
+		HttpReadTimeout() time.Duration
+		HttpWriteTimeout() time.Duration
+		HttpIdleTimeout() time.Duration
+		HttpMaxHeaderBytes() int
+		HttpMaxBodyBytes() int64
+		HttpTrustedProxies() []string
+		HttpCORSAllowOrigins() []string
+		HttpCORSAllowCredentials() bool
+		HttpCORSMaxAge() time.Duration
+		HttpHSTSMaxAge() time.Duration
+		HttpHSTSIncludeSubdomains() bool
+		HttpFrameOptions() string
+		HttpContentTypeNosniff() bool
+		AdminAddress() string
+		SpanUrl() string
+		OpenAPIValidationEnabled() bool
 		DatabaseDsn() string
-		CacheAddress() string
+		CacheMode() string
+		CacheAddresses() []string
+		CacheUsername() string
+		CachePassword() string
+		CacheDB() int
+		CacheMasterName() string
+		CacheSentinelPassword() string
+		CacheTLS() bool
+		CacheTLSCAFile() string
+		CacheTLSServerName() string
+		CacheDialTimeout() time.Duration
+		CacheReadTimeout() time.Duration
+		CacheWriteTimeout() time.Duration
+		CachePoolSize() int
+		CacheMinIdleConns() int
+		CacheStaleTTL() time.Duration
+		CacheTTLJitter() float64
+		CacheRefreshLock() bool
+		RateLimitEnabled() bool
+		RateLimitAlgorithm() string
+		RateLimitLimit() int
+		RateLimitPeriod() time.Duration
+		RateLimitRoutes() []string
+		RateLimitKey() string
+		AuthEnabled() bool
+		AuthJWKSFile() string
+		AuthJWKSUrl() string
+		AuthJWKSCacheTTL() time.Duration
+		AuthJWTIssuer() string
+		AuthJWTAudience() string
+		AuthRolesClaim() string
+		AuthTenantClaim() string
+		AuthApiKeysEnabled() bool
+		LogLevel() string
+		LogPackageLevels() []string
+		LogStdoutLevel() string
+		LogStdoutFormat() string
+		LogFilePath() string
+		LogFileLevel() string
+		LogFileFormat() string
+		LogFileMaxSizeMB() int
+		LogFileMaxAgeDays() int
+		LogFileMaxBackups() int
+		LogFileCompress() bool
+		LogFileRotateInterval() time.Duration
+		LogSyslogAddress() string
+		LogSyslogLevel() string
+		LogSyslogFormat() string
+		LogSamplingEnabled() bool
+		LogSamplingFirst() int
+		LogSamplingThereafter() int
+		LogSamplingInterval() time.Duration
+		LogRedactEnabled() bool
+		LogRedactKeys() []string
+		LogRedactPatterns() []string
 	}
 	Handlers interface {
 		Generic
@@ -72,6 +203,10 @@ type (
 		GetUser(ctx context.Context, u *models.User) error
 		GetUsers(ctx context.Context, u *[]models.User) error
 		DeleteUser(ctx context.Context, u *models.User) error
+		CreateApiKey(ctx context.Context, key *models.ApiKey) (string, error)
+		GetApiKeys(ctx context.Context, keys *[]models.ApiKey) error
+		RevokeApiKey(ctx context.Context, key *models.ApiKey) error
+		AuthenticateApiKey(ctx context.Context, plain string) (*models.ApiKey, error)
 	}
 
 	Sis interface {
@@ -81,8 +216,16 @@ type (
 		Cache() Cache
 		WithLogger(d Logger) Sis
 		Logger() Logger
+		WithValidator(d Validator) Sis
+		Validator() Validator
+		WithSpans(d Spans) Sis
+		Spans() Spans
+		WithMetrics(d Metrics) Sis
+		Metrics() Metrics
 		WithHttpServer(d HttpServer) Sis
 		HttpServer() HttpServer
+		WithAdminServer(d AdminServer) Sis
+		AdminServer() AdminServer
 		WithHandlers(d Handlers) Sis
 		Handlers() Handlers
 		WithEnvironment(d Environment) Sis
@@ -101,7 +244,10 @@ type (
 		validator   Validator
 		cache       Cache
 		log         Logger
+		spans       Spans
+		metrics     Metrics
 		httpServer  HttpServer
+		adminServer AdminServer
 		handlers    Handlers
 		environment Environment
 	}
@@ -112,7 +258,10 @@ func New() *sisImpl {
 		database:    NewNoopDatabase(),
 		cache:       NewNoopCache(),
 		log:         NewNoopLogger(),
+		spans:       NewNoopSpans(),
+		metrics:     NewNoopMetrics(),
 		httpServer:  NewNoopHttpServer(),
+		adminServer: NewNoopAdminServer(),
 		handlers:    NewNoopHandlers(),
 		environment: NewNoopEnvironment(),
 		validator:   NewNoopValidator(),
@@ -121,15 +270,28 @@ func New() *sisImpl {
 
 func (s *sisImpl) Init() error {
 	s.ctx, s.cancel = context.WithCancel(context.Background())
+	if err := s.Environment().Init(s.ctx); err != nil {
+		return err
+	}
 	if err := s.Logger().Init(s.ctx); err != nil {
 		return err
 	}
-	if err := s.Environment().Init(s.ctx); err != nil {
+	// before the services registering their metrics
+	if err := s.Metrics().Init(s.ctx); err != nil {
+		return err
+	}
+	if err := s.Validator().Init(s.ctx); err != nil {
+		return err
+	}
+	if err := s.Spans().Init(s.ctx); err != nil {
 		return err
 	}
 	if err := s.HttpServer().Init(s.ctx); err != nil {
 		return err
 	}
+	if err := s.AdminServer().Init(s.ctx); err != nil {
+		return err
+	}
 	if err := s.Cache().Init(s.ctx); err != nil {
 		return err
 	}
@@ -147,16 +309,27 @@ func (s *sisImpl) Health(ctx context.Context) error {
 	if err := s.Environment().Health(s.ctx); err != nil {
 		return err
 	}
+	if err := s.Validator().Health(s.ctx); err != nil {
+		return err
+	}
+	if err := s.Spans().Health(s.ctx); err != nil {
+		return err
+	}
+	if err := s.Metrics().Health(s.ctx); err != nil {
+		return err
+	}
 	if err := s.HttpServer().Health(s.ctx); err != nil {
 		return err
 	}
+	if err := s.AdminServer().Health(s.ctx); err != nil {
+		return err
+	}
 	if err := s.Cache().Health(s.ctx); err != nil {
 		return err
 	}
 	if err := s.Database().Health(s.ctx); err != nil {
 		return err
 	}
-	s.Logger().Info(s.ctx, "All services initialized")
 	return nil
 }
 
@@ -171,6 +344,20 @@ func (s *sisImpl) Close() error {
 	if errC := s.httpServer.Close(); errC != nil {
 		err = fmt.Errorf("%v - %v", err, errC)
 	}
+	// after the http server, so health and metrics are served while it drains
+	if errC := s.adminServer.Close(); errC != nil {
+		err = fmt.Errorf("%v - %v", err, errC)
+	}
+	if errC := s.validator.Close(); errC != nil {
+		err = fmt.Errorf("%v - %v", err, errC)
+	}
+	// after the http server, so the spans of the last requests are reported
+	if errC := s.spans.Close(); errC != nil {
+		err = fmt.Errorf("%v - %v", err, errC)
+	}
+	if errC := s.metrics.Close(); errC != nil {
+		err = fmt.Errorf("%v - %v", err, errC)
+	}
 	if errC := s.log.Close(); errC != nil {
 		err = fmt.Errorf("%v - %v", err, errC)
 	}
@@ -209,6 +396,33 @@ func (s *sisImpl) Logger() Logger {
 	return s.log
 }
 
+func (s *sisImpl) WithValidator(d Validator) Sis {
+	s.validator = d.WithSis(s)
+	return s
+}
+
+func (s *sisImpl) Validator() Validator {
+	return s.validator
+}
+
+func (s *sisImpl) WithSpans(d Spans) Sis {
+	s.spans = d.WithSis(s)
+	return s
+}
+
+func (s *sisImpl) Spans() Spans {
+	return s.spans
+}
+
+func (s *sisImpl) WithMetrics(d Metrics) Sis {
+	s.metrics = d.WithSis(s)
+	return s
+}
+
+func (s *sisImpl) Metrics() Metrics {
+	return s.metrics
+}
+
 func (s *sisImpl) WithHttpServer(d HttpServer) Sis {
 	s.httpServer = d.WithSis(s)
 	return s
@@ -218,6 +432,15 @@ func (s *sisImpl) HttpServer() HttpServer {
 	return s.httpServer
 }
 
+func (s *sisImpl) WithAdminServer(d AdminServer) Sis {
+	s.adminServer = d.WithSis(s)
+	return s
+}
+
+func (s *sisImpl) AdminServer() AdminServer {
+	return s.adminServer
+}
+
 func (s *sisImpl) WithHandlers(d Handlers) Sis {
 	s.handlers = d.WithSis(s)
 	return s
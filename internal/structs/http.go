@@ -0,0 +1,19 @@
+package structs
+
+import "time"
+
+type (
+	// CORS lets browsers on AllowOrigins call the api, "*" allows any origin.
+	CORS struct {
+		AllowOrigins     []string
+		AllowCredentials bool
+		MaxAge           time.Duration
+	}
+	// SecureHeaders are the security headers of every response, zero values leave their header out.
+	SecureHeaders struct {
+		HSTSMaxAge            time.Duration
+		HSTSIncludeSubdomains bool
+		FrameOptions          string
+		ContentTypeNosniff    bool
+	}
+)
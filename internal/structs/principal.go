@@ -0,0 +1,22 @@
+package structs
+
+type (
+	// Principal is who a request is authenticated as.
+	Principal struct {
+		Subject string
+		Roles   []string
+		Tenant  string
+	}
+)
+
+// HasAnyRole reports whether p has at least one of roles.
+func (p *Principal) HasAnyRole(roles ...string) bool {
+	for _, role := range roles {
+		for _, r := range p.Roles {
+			if r == role {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package structs
+
+import (
+	"github.com/dalmarcogd/bpl-go/internal/errors"
+	"time"
+)
+
+const (
+	RateLimitTokenBucket   = "token_bucket"
+	RateLimitSlidingWindow = "sliding_window"
+)
+
+type (
+	// RateLimit allows Limit requests per Period.
+	RateLimit struct {
+		Algorithm string
+		Limit     int
+		Period    time.Duration
+	}
+	RateLimitResult struct {
+		Allowed    bool
+		Limit      int
+		Remaining  int
+		ResetAfter time.Duration
+		RetryAfter time.Duration
+	}
+)
+
+// Validate refuses limits that can not be applied.
+func (l RateLimit) Validate() error {
+	if l.Limit <= 0 || l.Period <= 0 {
+		return errors.RateLimitInvalid
+	}
+	return nil
+}